@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"db-bench/lib/report"
+)
+
+var errRegression = errors.New("regression detected")
+
+func compareCmd(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	th := report.DefaultThresholds
+	fs.Float64Var(&th.ThroughputDrop, "max-throughput-drop", th.ThroughputDrop, "relative throughput drop that counts as a regression")
+	fs.Float64Var(&th.LatencyIncrease, "max-latency-increase", th.LatencyIncrease, "relative p99/p99.9 increase that counts as a regression")
+	fs.Float64Var(&th.ErrorRateIncrease, "max-error-rate-increase", th.ErrorRateIncrease, "absolute error rate increase that counts as a regression")
+	fs.Float64Var(&th.Alpha, "alpha", th.Alpha, "significance level for the throughput t-test")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: dbbench compare [flags] base.json candidate.json [candidate.json...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return errors.New("need a base report and at least one candidate")
+	}
+
+	base, err := report.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	regressed := false
+	for _, path := range fs.Args()[1:] {
+		cand, err := report.Load(path)
+		if err != nil {
+			return err
+		}
+		c := report.Compare(base, cand, th)
+		c.Write(os.Stdout)
+		fmt.Println()
+		regressed = regressed || c.Regressed()
+	}
+	if regressed {
+		return errRegression
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"db-bench/lib"
+)
+
+func dropCmd(args []string) error {
+	flags := newConfigFlags("drop")
+	yes := flags.fs.Bool("yes", false, "confirm dropping the dataset")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	cfg, err := flags.load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !*yes {
+		return fmt.Errorf("refusing to drop %s table %q without --yes", cfg.DB, cfg.TableName)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	tester, err := lib.GetTester(cfg.DB, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize %s tester: %w", cfg.DB, err)
+	}
+	defer tester.Close()
+
+	if err := tester.Drop(ctx); err != nil {
+		return fmt.Errorf("failed to drop %s dataset: %w", cfg.DB, err)
+	}
+	log.Printf("Dropped %s table %q.", cfg.DB, cfg.TableName)
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"db-bench/lib"
+	"db-bench/lib/report"
+	"db-bench/lib/runner"
+)
+
+func findMaxCmd(args []string) error {
+	flags := newConfigFlags("find-max")
+	flags.fs.Duration("slo-p99", 0, "p99 latency the SLO allows")
+	flags.fs.Float64("slo-error-rate", 0, "error rate the SLO allows, e.g. 0.001")
+	flags.fs.Float64("min-rate", 0, "ops/sec of the first trial")
+	flags.fs.Float64("max-rate", 0, "highest ops/sec to try")
+	flags.fs.Duration("trial-duration", 0, "measured duration of each trial")
+	metricsAddr := flags.fs.String("metrics-addr", ":8081", "address to serve Prometheus /metrics on")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	cfg, err := flags.load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	serveMetrics(*metricsAddr)
+	sleep(ctx, 2*time.Second)
+
+	tester, err := lib.GetTester(cfg.DB, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize %s tester: %w", cfg.DB, err)
+	}
+	defer tester.Close()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	slo := cfg.FindMax.SLO
+	log.Printf("Searching max throughput of %s at p99 <= %v, error rate <= %.4f%%", cfg.DB, slo.P99, 100*slo.ErrorRate)
+	search := runner.FindMax(ctx, cfg, tester)
+	rep := report.NewFindMax(search)
+	if rep == nil {
+		return errors.New("search interrupted before the first trial finished")
+	}
+	if search.Aborted {
+		log.Printf("Search for %s aborted after %d trials, reporting partial results.", cfg.DB, len(search.Trials))
+	}
+	if rep.FindMax.MaxOpsPerSec > 0 {
+		log.Printf("Max throughput of %s at SLO: %.0f ops/s (p99 %.3fms)", cfg.DB, rep.FindMax.MaxOpsPerSec, rep.Total.Latency.P99)
+	} else {
+		log.Printf("No trial of %s met the SLO", cfg.DB)
+	}
+	writeReport(rep, cfg)
+	return nil
+}
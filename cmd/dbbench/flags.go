@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"db-bench/lib/conf"
+)
+
+// configFlags holds the flags shared by every command that talks to a database.
+// Each flag overrides the matching config.yaml key only when set explicitly.
+type configFlags struct {
+	fs         *flag.FlagSet
+	configPath string
+	db         string
+	set        setFlag
+}
+
+// setFlag collects repeated --set key=value overrides.
+type setFlag map[string]any
+
+func (s setFlag) String() string { return "" }
+
+func (s setFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	s[key] = val
+	return nil
+}
+
+// flagKeys maps flag names to config keys; "%s" is replaced with the database name.
+var flagKeys = map[string]string{
+	"uri":             "%s.uri",
+	"db-name":         "%s.dbName",
+	"workers":         "workerCount",
+	"records":         "recordCount",
+	"table":           "tableName",
+	"duration":        "testDuration",
+	"connect-timeout": "connectTimeout",
+	"key-dist":        "keyDistribution.type",
+	"workload":        "workload.preset",
+	"rate":            "rate.targetOpsPerSec",
+	"warmup":          "phases.warmup",
+	"cooldown":        "phases.cooldown",
+	"report-dir":      "report.dir",
+	"report-format":   "report.formats",
+	"slo-p99":         "findMax.slo.p99",
+	"slo-error-rate":  "findMax.slo.errorRate",
+	"min-rate":        "findMax.minOpsPerSec",
+	"max-rate":        "findMax.maxOpsPerSec",
+	"trial-duration":  "findMax.trialDuration",
+	"batch-size":      "%s.seed.batchSize",
+	"seed-workers":    "%s.seed.workers",
+	"resume":          "seed.resume",
+	"samples":         "seed.verifySamples",
+	"projection":      "read.projection",
+	"validate":        "read.validate",
+	"multiget-size":   "read.multiGet.batchSize",
+	"scan-mode":       "scan.mode",
+	"query-attr":      "query.attribute",
+	"query-index":     "query.index",
+	"schema":          "schema",
+	"consistency":     "consistency",
+	"levels":          "matrix.levels",
+}
+
+// listFlags are comma-separated flags that map onto list config keys.
+var listFlags = map[string]bool{
+	"report-format": true,
+	"levels":        true,
+}
+
+func newConfigFlags(name string) *configFlags {
+	f := &configFlags{
+		fs:  flag.NewFlagSet(name, flag.ExitOnError),
+		set: setFlag{},
+	}
+	f.fs.StringVar(&f.configPath, "config", os.Getenv("CONFIG_PATH"), "path to config.yaml (default $CONFIG_PATH or ./config.yaml)")
+	f.fs.StringVar(&f.db, "db", "", "database type, see 'dbbench list'")
+	f.fs.String("uri", "", "connection URI")
+	f.fs.String("db-name", "", "database, keyspace or path name")
+	f.fs.Int("workers", 0, "number of concurrent workers")
+	f.fs.Int("records", 0, "number of records to seed and read")
+	f.fs.String("table", "", "table, collection or key prefix name")
+	f.fs.Duration("duration", 0, "measured benchmark duration")
+	f.fs.Duration("connect-timeout", 0, "connection timeout")
+	f.fs.String("key-dist", "", "key distribution: uniform, zipfian, hotspot, latest, sequential, exponential")
+	f.fs.String("workload", "", "YCSB workload preset: a, b, c, d, e, f")
+	f.fs.Float64("rate", 0, "target ops/sec for open-loop load (0 = closed loop)")
+	f.fs.String("projection", "", "what a read fetches: id, row or decode")
+	f.fs.Float64("validate", 0, "fraction of reads to check against the payload generator")
+	f.fs.Int("multiget-size", 0, "keys per multi-get")
+	f.fs.String("scan-mode", "", "scan pagination: range, keyset or offset")
+	f.fs.String("query-attr", "", "dotted path of the targeting_rules attribute queries filter on")
+	f.fs.String("schema", "", "schema variant from <db>.schemas, see 'dbbench list -v'")
+	f.fs.String("consistency", "", "consistency or isolation level, see 'dbbench list -v'")
+	f.fs.Duration("warmup", 0, "warm-up before the measured duration")
+	f.fs.Duration("cooldown", 0, "cool-down after the measured duration")
+	f.fs.String("report-dir", "", "directory for the end-of-run report")
+	f.fs.String("report-format", "", "comma-separated report formats: json, csv, md")
+	f.fs.Var(f.set, "set", "override any config key, e.g. --set postgres.uri=... (repeatable)")
+	return f
+}
+
+func (f *configFlags) parse(args []string) error {
+	return f.fs.Parse(args)
+}
+
+// load reads the config for the selected database with flag overrides applied.
+func (f *configFlags) load() (*conf.Config, error) {
+	if f.db == "" {
+		return nil, errors.New("--db is required")
+	}
+
+	overrides := map[string]any{}
+	for k, v := range f.set {
+		overrides[k] = v
+	}
+	f.fs.Visit(func(fl *flag.Flag) {
+		key, ok := flagKeys[fl.Name]
+		if !ok {
+			return
+		}
+		if strings.Contains(key, "%s") {
+			key = fmt.Sprintf(key, f.db)
+		}
+		if listFlags[fl.Name] {
+			overrides[key] = strings.Split(fl.Value.String(), ",")
+			return
+		}
+		overrides[key] = fl.Value.(flag.Getter).Get()
+	})
+
+	return conf.LoadConfig(f.db, f.configPath, overrides)
+}
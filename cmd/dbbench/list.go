@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"db-bench/lib"
+)
+
+func listCmd(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	verbose := fs.Bool("v", false, "show config keys, capabilities, schema settings, driver options and consistency levels")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	for _, b := range lib.ListBackends() {
+		if !*verbose {
+			fmt.Println(b.Name)
+			continue
+		}
+		caps := b.Capabilities.String()
+		if caps == "" {
+			caps = "-"
+		}
+		fmt.Printf("%s\n  capabilities: %s\n", b.Name, caps)
+		for _, k := range b.Config {
+			fmt.Printf("  %-22s %s\n", b.Name+"."+k.Key, k.Description)
+		}
+		if len(b.Schema) > 0 {
+			fmt.Printf("  schema settings (%s.schemas.<name>):\n", b.Name)
+		}
+		for _, k := range b.Schema {
+			fmt.Printf("    %-20s %s\n", k.Key, k.Description)
+		}
+		if len(b.Options) > 0 {
+			fmt.Printf("  driver options (%s.options):\n", b.Name)
+		}
+		for _, k := range b.Options {
+			fmt.Printf("    %-20s %s\n", k.Key, k.Description)
+		}
+		if len(b.Consistency) > 0 {
+			fmt.Printf("  consistency levels:\n")
+		}
+		for _, k := range b.Consistency {
+			fmt.Printf("    %-20s %s\n", k.Key, k.Description)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{name: "seed", summary: "create the schema and load RecordCount rows", run: seedCmd},
+	{name: "drop", summary: "drop the table (and namespace) created by seed", run: dropCmd},
+	{name: "verify", summary: "check that the seeded data is complete and intact", run: verifyCmd},
+	{name: "run", summary: "run the configured workload for TestDuration", run: runCmd},
+	{name: "find-max", summary: "search the highest target rate that meets the SLO", run: findMaxCmd},
+	{name: "matrix", summary: "run the workload at each consistency level and compare them", run: matrixCmd},
+	{name: "compare", summary: "compare saved JSON reports and fail on regressions", run: compareCmd},
+	{name: "list", summary: "list registered database backends", run: listCmd},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: dbbench <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'dbbench <command> -h' for command flags.")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage()
+		return
+	}
+
+	for _, c := range commands {
+		if c.name == name {
+			if err := c.run(os.Args[2:]); err != nil {
+				log.Fatalf("%s: %v", name, err)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"db-bench/lib"
+	"db-bench/lib/conf"
+	"db-bench/lib/report"
+	"db-bench/lib/runner"
+)
+
+func matrixCmd(args []string) error {
+	flags := newConfigFlags("matrix")
+	flags.fs.String("levels", "", "comma-separated consistency levels to run (default: all levels of the database)")
+	metricsAddr := flags.fs.String("metrics-addr", ":8081", "address to serve Prometheus /metrics on")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	cfg, err := flags.load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	levels := cfg.Matrix.Levels
+	if len(levels) == 0 {
+		levels = lib.ConsistencyLevels(cfg.DB)
+	}
+	if len(levels) == 0 {
+		return fmt.Errorf("%s has no consistency levels to compare", cfg.DB)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	serveMetrics(*metricsAddr)
+	sleep(ctx, 2*time.Second)
+
+	var runs []report.Level
+	for i, level := range levels {
+		if ctx.Err() != nil {
+			break
+		}
+		c := *cfg
+		c.Consistency = level
+		tester, err := lib.GetTester(c.DB, &c)
+		if err != nil {
+			return fmt.Errorf("failed to initialize %s tester at %s: %w", c.DB, level, err)
+		}
+		// The previous level changed the table; start every level from the
+		// same data.
+		if i > 0 && c.Workload.Writes() {
+			if tester, err = reseed(ctx, tester, &c); err != nil {
+				return err
+			}
+		}
+		log.Printf("Level %d/%d: %s", i+1, len(levels), c.Consistency)
+		res := runner.Run(ctx, &c, tester)
+		tester.Close()
+		runs = append(runs, report.Level{Config: &c, Result: res})
+		total := res.Stats.Total()
+		log.Printf("Level %s: %d ops, %d errors in %v", c.Consistency, total.Count, total.Errors, res.End.Sub(res.Start))
+		if res.Aborted {
+			log.Printf("Matrix for %s aborted at %s, reporting partial results.", c.DB, c.Consistency)
+			break
+		}
+	}
+	rep := report.NewMatrix(runs)
+	if rep == nil {
+		return errors.New("matrix interrupted before the first level ran")
+	}
+	for _, l := range rep.Matrix {
+		t := l.Total
+		log.Printf("%s: %.1f ops/s, error rate %.4f%%, p50 %.3fms, p99 %.3fms",
+			l.Consistency, t.Throughput, 100*t.ErrorRate, t.Latency.P50, t.Latency.P99)
+	}
+	writeReport(rep, cfg)
+	return nil
+}
+
+// reseed drops the dataset of tester and seeds it again, returning the
+// tester to run with.
+func reseed(ctx context.Context, tester lib.DatabaseTester, cfg *conf.Config) (lib.DatabaseTester, error) {
+	log.Printf("Re-seeding %s table %q", cfg.DB, cfg.TableName)
+	err := tester.Drop(ctx)
+	tester.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to drop %s dataset: %w", cfg.DB, err)
+	}
+	// Reconnect: some testers create their namespace (e.g. the Cassandra
+	// keyspace) when they connect.
+	if tester, err = lib.GetTester(cfg.DB, cfg); err != nil {
+		return nil, fmt.Errorf("failed to initialize %s tester: %w", cfg.DB, err)
+	}
+	if err := tester.Seed(ctx); err != nil {
+		tester.Close()
+		return nil, fmt.Errorf("seeding failed for %s: %w", cfg.DB, err)
+	}
+	return tester, nil
+}
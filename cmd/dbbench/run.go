@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"db-bench/lib"
+	"db-bench/lib/conf"
+	"db-bench/lib/report"
+	"db-bench/lib/runner"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func runCmd(args []string) error {
+	flags := newConfigFlags("run")
+	metricsAddr := flags.fs.String("metrics-addr", ":8081", "address to serve Prometheus /metrics on")
+	linger := flags.fs.Duration("linger", 10*time.Second, "how long to keep serving metrics after the run so Prometheus can scrape")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	cfg, err := flags.load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	serveMetrics(*metricsAddr)
+	sleep(ctx, 2*time.Second)
+
+	tester, err := lib.GetTester(cfg.DB, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize %s tester: %w", cfg.DB, err)
+	}
+	defer tester.Close()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	res := runner.Run(ctx, cfg, tester)
+	if res.Aborted {
+		log.Printf("Test for %s aborted after %v of measurement, reporting partial results.", cfg.DB, res.End.Sub(res.Start))
+	} else {
+		log.Printf("Test for %s completed. Duration: %v", cfg.DB, res.End.Sub(res.Start))
+	}
+
+	rep := report.New(cfg, res)
+	total := rep.Total
+	log.Printf("%d ops, %d errors, %.1f ops/s, p50 %.3fms, p99 %.3fms, p99.9 %.3fms",
+		total.Count, total.Errors, total.Throughput, total.Latency.P50, total.Latency.P99, total.Latency.P999)
+	if rep.Knee > 0 {
+		knee := rep.Stages[rep.Knee-1]
+		log.Printf("Saturation after stage %d: %d workers, %.1f ops/s, p99 %.3fms",
+			knee.Stage, knee.Workers, knee.Total.Throughput, knee.Total.Latency.P99)
+	}
+	for _, e := range rep.ErrorReasons {
+		log.Printf("%s errors: %d %s", e.Op, e.Count, e.Reason)
+	}
+	if v := rep.Validation; v != nil && v.Failed > 0 {
+		log.Printf("Warning: %d of %d checked reads returned a missing or wrong row", v.Failed, v.Checked)
+	}
+	writeReport(rep, cfg)
+
+	sleep(ctx, *linger)
+	return nil
+}
+
+func serveMetrics(addr string) {
+	go func() {
+		http.Handle("/metrics", promhttp.Handler())
+		log.Fatal(http.ListenAndServe(addr, nil))
+	}()
+}
+
+func writeReport(rep *report.Report, cfg *conf.Config) {
+	paths, err := rep.Write(cfg.Report.Dir, cfg.Report.Formats)
+	if err != nil {
+		log.Printf("Failed to write report: %v", err)
+	}
+	for _, p := range paths {
+		log.Printf("Report written to %s", p)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"db-bench/lib"
+)
+
+func seedCmd(args []string) error {
+	flags := newConfigFlags("seed")
+	flags.fs.Int("batch-size", 0, "rows per insert batch")
+	flags.fs.Int("seed-workers", 0, "number of concurrent insert batches")
+	flags.fs.Bool("resume", false, "continue from the highest id already present")
+	flags.fs.Bool("query-index", false, "index query.attribute for the query workload")
+	reset := flags.fs.Bool("reset", false, "drop the existing dataset before seeding")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	cfg, err := flags.load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if *reset && cfg.Seed.Resume {
+		return fmt.Errorf("--reset and --resume are mutually exclusive")
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	tester, err := lib.GetTester(cfg.DB, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize %s tester: %w", cfg.DB, err)
+	}
+	defer func() {
+		if tester != nil {
+			tester.Close()
+		}
+	}()
+
+	if *reset {
+		if err := tester.Drop(ctx); err != nil {
+			return fmt.Errorf("failed to drop %s dataset: %w", cfg.DB, err)
+		}
+		log.Printf("Dropped %s table %q.", cfg.DB, cfg.TableName)
+		// Reconnect: some testers create their namespace (e.g. the Cassandra
+		// keyspace) when they connect.
+		tester.Close()
+		if tester, err = lib.GetTester(cfg.DB, cfg); err != nil {
+			return fmt.Errorf("failed to initialize %s tester: %w", cfg.DB, err)
+		}
+	}
+
+	if err := tester.Seed(ctx); err != nil {
+		return fmt.Errorf("seeding failed for %s: %w", cfg.DB, err)
+	}
+	log.Printf("Seeding for %s completed.", cfg.DB)
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"db-bench/lib"
+	"db-bench/lib/seed"
+)
+
+func verifyCmd(args []string) error {
+	flags := newConfigFlags("verify")
+	flags.fs.Int("samples", 0, "number of random rows to compare with the generator")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+	cfg, err := flags.load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	tester, err := lib.GetTester(cfg.DB, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize %s tester: %w", cfg.DB, err)
+	}
+	defer tester.Close()
+
+	if err := seed.Verify(ctx, cfg.DB, cfg, tester); err != nil {
+		return fmt.Errorf("%s: %w", cfg.DB, err)
+	}
+	log.Printf("Seed of %s verified.", cfg.DB)
+	return nil
+}
@@ -1,26 +0,0 @@
-package main
-
-import (
-	"context"
-	"db-bench/lib"
-	"db-bench/lib/conf"
-	"log"
-	"os"
-)
-
-func main() {
-	configPath := os.Getenv("CONFIG_PATH")
-	cfg, err := conf.LoadConfig("ydb", configPath)
-	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
-	}
-	tester, err := lib.GetTester("ydb", cfg)
-	if err != nil {
-		log.Fatalf("Failed to initialize ydb tester: %v", err)
-	}
-	defer tester.Close()
-	if err := tester.Seed(context.Background()); err != nil {
-		log.Fatalf("Seeding failed for ydb: %v", err)
-	}
-	log.Println("Seeding for ydb completed.")
-}
@@ -0,0 +1,201 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"db-bench/lib/conf"
+	"db-bench/lib/seed"
+	"db-bench/lib/workload"
+)
+
+type Tester interface {
+	workload.Operator
+	workload.BatchReader
+	workload.Scanner
+	workload.Classifier
+	seed.Inspector
+	Seed(ctx context.Context) error
+	// Drop removes everything Seed (and the constructor) created.
+	Drop(ctx context.Context) error
+	Close()
+}
+
+// Factory opens a connection to the database described by cfg.
+type Factory func(ctx context.Context, cfg *conf.Config) (Tester, error)
+
+// ConfigKey describes a key the backend reads from its section of config.yaml.
+type ConfigKey struct {
+	Key         string
+	Description string
+}
+
+type Capability uint
+
+const (
+	// CapBatchSeed means Seed writes rows in batches rather than one by one.
+	CapBatchSeed Capability = 1 << iota
+	// CapNativeJSON means targeting_rules is stored in a JSON-aware column type.
+	CapNativeJSON
+	// CapJSONQuery means the tester is a workload.Querier, so the query
+	// workload can filter rows by an attribute of targeting_rules.
+	CapJSONQuery
+)
+
+var capabilityNames = []struct {
+	c    Capability
+	name string
+}{
+	{CapBatchSeed, "batch-seed"},
+	{CapNativeJSON, "native-json"},
+	{CapJSONQuery, "json-query"},
+}
+
+func (c Capability) Has(other Capability) bool { return c&other == other }
+
+func (c Capability) String() string {
+	var names []string
+	for _, n := range capabilityNames {
+		if c.Has(n.c) {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+type Backend struct {
+	Name         string
+	New          Factory
+	Config       []ConfigKey
+	Capabilities Capability
+	// Schema lists the settings a schema variant in <name>.schemas may set.
+	Schema []ConfigKey
+	// Options lists the driver settings <name>.options may set.
+	Options []ConfigKey
+	// Consistency lists the read consistency levels the top-level
+	// consistency key may select, the default first.
+	Consistency []ConfigKey
+}
+
+// CheckSettings rejects schema and driver settings and consistency levels of
+// cfg that b does not know and restores the spelling of the others, which
+// viper lowercases.
+func (b Backend) CheckSettings(cfg *conf.Config) error {
+	var err error
+	where := fmt.Sprintf("%s.schemas.%s", b.Name, cfg.Schema.Name)
+	if cfg.Schema.Settings, err = canonical(cfg.Schema.Settings, b.Schema, where); err != nil {
+		return err
+	}
+	if cfg.Options, err = canonical(cfg.Options, b.Options, b.Name+".options"); err != nil {
+		return err
+	}
+	if cfg.Consistency != "" {
+		if cfg.Consistency, err = b.level(cfg.Consistency); err != nil {
+			return err
+		}
+	}
+	for i, l := range cfg.Matrix.Levels {
+		if cfg.Matrix.Levels[i], err = b.level(l); err != nil {
+			return fmt.Errorf("matrix.levels: %w", err)
+		}
+	}
+	return nil
+}
+
+// Levels returns the names of b's consistency levels, the default first.
+func (b Backend) Levels() []string {
+	levels := make([]string, len(b.Consistency))
+	for i, k := range b.Consistency {
+		levels[i] = k.Key
+	}
+	return levels
+}
+
+func (b Backend) level(name string) (string, error) {
+	if len(b.Consistency) == 0 {
+		return "", fmt.Errorf("%s has no consistency levels to choose from", b.Name)
+	}
+	i := slices.IndexFunc(b.Consistency, func(k ConfigKey) bool { return strings.EqualFold(k.Key, name) })
+	if i < 0 {
+		return "", fmt.Errorf("unknown %s consistency level %q (available: %s)", b.Name, name, strings.Join(b.Levels(), ", "))
+	}
+	return b.Consistency[i].Key, nil
+}
+
+func canonical(settings conf.Settings, keys []ConfigKey, where string) (conf.Settings, error) {
+	if len(settings) == 0 {
+		return settings, nil
+	}
+	out := make(conf.Settings, len(settings))
+	for key, value := range settings {
+		i := slices.IndexFunc(keys, func(k ConfigKey) bool { return strings.EqualFold(k.Key, key) })
+		if i < 0 {
+			return nil, fmt.Errorf("%s: unknown setting %q", where, key)
+		}
+		out[keys[i].Key] = value
+	}
+	return out, nil
+}
+
+// CommonConfig is the part of the config schema every backend shares.
+var CommonConfig = []ConfigKey{
+	{Key: "uri", Description: "connection URI"},
+	{Key: "dbName", Description: "database, keyspace or path name"},
+}
+
+var (
+	mu       sync.RWMutex
+	backends = map[string]Backend{}
+)
+
+// Register makes a backend available by name. It is meant to be called from
+// the init function of the backend package and panics on invalid or duplicate
+// registrations so mistakes surface at startup.
+func Register(b Backend) {
+	if b.Name == "" {
+		panic("backend: Register called with empty name")
+	}
+	if b.New == nil {
+		panic(fmt.Sprintf("backend: Register %q with nil factory", b.Name))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := backends[b.Name]; dup {
+		panic(fmt.Sprintf("backend: Register called twice for %q", b.Name))
+	}
+	backends[b.Name] = b
+}
+
+func Lookup(name string) (Backend, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	b, ok := backends[name]
+	return b, ok
+}
+
+// List returns all registered backends sorted by name.
+func List() []Backend {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]Backend, 0, len(backends))
+	for _, b := range backends {
+		list = append(list, b)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Names returns the names of all registered backends sorted alphabetically.
+func Names() []string {
+	list := List()
+	names := make([]string, len(list))
+	for i, b := range list {
+		names[i] = b.Name
+	}
+	return names
+}
@@ -0,0 +1,52 @@
+package backend_test
+
+import (
+	"testing"
+
+	"db-bench/lib/backend"
+
+	_ "db-bench/lib/cassandra"
+	_ "db-bench/lib/etcd"
+	_ "db-bench/lib/mongo"
+	_ "db-bench/lib/mysql"
+	_ "db-bench/lib/postgre"
+	_ "db-bench/lib/ydb"
+)
+
+func TestBackendsRegistered(t *testing.T) {
+	want := map[string]backend.Capability{
+		"cassandra": backend.CapBatchSeed,
+		"etcd":      backend.CapBatchSeed,
+		"mongo":     backend.CapBatchSeed | backend.CapNativeJSON | backend.CapJSONQuery,
+		"mysql":     backend.CapBatchSeed | backend.CapNativeJSON | backend.CapJSONQuery,
+		"postgres":  backend.CapBatchSeed | backend.CapNativeJSON | backend.CapJSONQuery,
+		"ydb":       backend.CapBatchSeed | backend.CapNativeJSON | backend.CapJSONQuery,
+	}
+	got := map[string]backend.Capability{}
+	for _, b := range backend.List() {
+		got[b.Name] = b.Capabilities
+	}
+	for name, caps := range want {
+		c, ok := got[name]
+		if !ok {
+			t.Errorf("%s is not registered", name)
+			continue
+		}
+		if c != caps {
+			t.Errorf("%s capabilities %q, want %q", name, c, caps)
+		}
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	b, ok := backend.Lookup("postgres")
+	if !ok {
+		t.Fatal("postgres is not registered")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("registering postgres twice did not panic")
+		}
+	}()
+	backend.Register(b)
+}
@@ -0,0 +1,26 @@
+package cassandra
+
+import (
+	"db-bench/lib/backend"
+
+	"github.com/gocql/gocql"
+)
+
+var consistencyLevels = []backend.ConfigKey{
+	{Key: "one", Description: "one replica answers (default)"},
+	{Key: "local_one", Description: "one replica of the local datacenter answers"},
+	{Key: "quorum", Description: "a majority of all replicas answers"},
+	{Key: "local_quorum", Description: "a majority of the local datacenter's replicas answers"},
+	{Key: "all", Description: "every replica answers"},
+}
+
+// sessionConsistency maps the consistency key onto the consistency of the
+// session, which reads and writes alike (seed included) run at.
+var sessionConsistency = map[string]gocql.Consistency{
+	"":             gocql.One,
+	"one":          gocql.One,
+	"local_one":    gocql.LocalOne,
+	"quorum":       gocql.Quorum,
+	"local_quorum": gocql.LocalQuorum,
+	"all":          gocql.All,
+}
@@ -0,0 +1,32 @@
+package cassandra
+
+import (
+	"errors"
+
+	"db-bench/lib/workload"
+
+	"github.com/gocql/gocql"
+)
+
+func (t *CassandraTester) ClassifyError(err error) string {
+	var reqErr gocql.RequestError
+	switch {
+	case errors.As(err, &reqErr):
+		switch reqErr.Code() {
+		case gocql.ErrCodeReadTimeout, gocql.ErrCodeWriteTimeout:
+			return workload.ReasonTimeout
+		case gocql.ErrCodeUnavailable:
+			return workload.ReasonUnavailable
+		case gocql.ErrCodeOverloaded:
+			return workload.ReasonThrottled
+		}
+	case errors.Is(err, gocql.ErrTimeoutNoResponse):
+		return workload.ReasonTimeout
+	case errors.Is(err, gocql.ErrNoConnections), errors.Is(err, gocql.ErrConnectionClosed),
+		errors.Is(err, gocql.ErrUnavailable), errors.Is(err, gocql.ErrSessionClosed):
+		return workload.ReasonUnavailable
+	case errors.Is(err, gocql.ErrNotFound):
+		return workload.ReasonNotFound
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+package cassandra
+
+import (
+	"context"
+	"db-bench/lib/conf"
+	"db-bench/lib/workload"
+	"errors"
+	"fmt"
+
+	"github.com/gocql/gocql"
+)
+
+type queries struct {
+	read, get, update, insert, delete string
+	readMany, getMany                 string
+	// by scan mode, see Scan
+	scanIDs, scanRows map[string]string
+}
+
+func newQueries(table string, updateIfExists bool) queries {
+	q := queries{
+		read: fmt.Sprintf("SELECT id FROM %s WHERE id = ?", table),
+		get:  fmt.Sprintf("SELECT experiment_name, targeting_rules FROM %s WHERE id = ?", table),
+		// Cassandra writes are upserts: without IF EXISTS, UPDATE recreates a
+		// deleted row.
+		update: fmt.Sprintf("UPDATE %s SET experiment_name = ?, targeting_rules = ? WHERE id = ?", table),
+		insert: fmt.Sprintf("INSERT INTO %s (id, experiment_name, targeting_rules) VALUES (?, ?, ?)", table),
+		delete: fmt.Sprintf("DELETE FROM %s WHERE id = ?", table),
+
+		readMany: fmt.Sprintf("SELECT id FROM %s WHERE id IN ?", table),
+		getMany:  fmt.Sprintf("SELECT id, experiment_name, targeting_rules FROM %s WHERE id IN ?", table),
+
+		scanIDs:  scanQueries(table, "id"),
+		scanRows: scanQueries(table, "id, experiment_name, targeting_rules"),
+	}
+	if updateIfExists {
+		q.update += " IF EXISTS"
+	}
+	return q
+}
+
+func scanQueries(table, columns string) map[string]string {
+	return map[string]string{
+		conf.ScanRange:  fmt.Sprintf("SELECT %s FROM %s WHERE token(id) >= token(?) LIMIT ?", columns, table),
+		conf.ScanKeyset: fmt.Sprintf("SELECT %s FROM %s WHERE token(id) > token(?) LIMIT ?", columns, table),
+		conf.ScanOffset: fmt.Sprintf("SELECT %s FROM %s LIMIT ?", columns, table),
+	}
+}
+
+func (t *CassandraTester) Read(ctx context.Context, id int64) error {
+	if t.cfg.Read.Projection != conf.ProjectionID {
+		return workload.ReadRow(ctx, t.cfg.Read.Projection, id, t.Get)
+	}
+	var idRead int64
+	err := t.session.Query(t.q.read, id).WithContext(ctx).Consistency(t.consistency).Scan(&idRead)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return workload.ErrNotFound
+	}
+	return err
+}
+
+// ReadMany uses a single IN query, which the coordinator splits into one
+// read per partition; read.multiGet.mode: parallel issues them from the
+// client instead.
+func (t *CassandraTester) ReadMany(ctx context.Context, ids []int64) error {
+	query := t.q.getMany
+	if t.cfg.Read.Projection == conf.ProjectionID {
+		query = t.q.readMany
+	}
+	iter := t.session.Query(query, ids).WithContext(ctx).Consistency(t.consistency).Iter()
+	found := 0
+	var rule conf.ExperimentRule
+	for {
+		var ok bool
+		if t.cfg.Read.Projection == conf.ProjectionID {
+			ok = iter.Scan(&rule.ID)
+		} else {
+			ok = iter.Scan(&rule.ID, &rule.ExperimentName, &rule.TargetingRules)
+		}
+		if !ok {
+			break
+		}
+		found++
+		if err := workload.DecodeRow(t.cfg.Read.Projection, rule); err != nil {
+			iter.Close()
+			return err
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+	return workload.Found(found, len(ids))
+}
+
+// Scan walks the token ring: id is the partition key, so rows are only
+// ordered by its token. A range is the Limit rows from the token of From on.
+// CQL has no OFFSET, so an offset page reads and discards the rows before it.
+func (t *CassandraTester) Scan(ctx context.Context, q workload.ScanQuery) (int, int64, error) {
+	query := t.q.scanRows[q.Mode]
+	if t.cfg.Read.Projection == conf.ProjectionID {
+		query = t.q.scanIDs[q.Mode]
+	}
+	args := []any{q.From, q.Limit}
+	if q.Mode == conf.ScanOffset {
+		args = []any{q.Offset + q.Limit}
+	}
+	iter := t.session.Query(query, args...).WithContext(ctx).Consistency(t.consistency).Iter()
+	n, seen := 0, 0
+	var rule conf.ExperimentRule
+	for {
+		var ok bool
+		if t.cfg.Read.Projection == conf.ProjectionID {
+			ok = iter.Scan(&rule.ID)
+		} else {
+			ok = iter.Scan(&rule.ID, &rule.ExperimentName, &rule.TargetingRules)
+		}
+		if !ok {
+			break
+		}
+		if seen++; q.Mode == conf.ScanOffset && seen <= q.Offset {
+			continue
+		}
+		n++
+		if err := workload.DecodeRow(t.cfg.Read.Projection, rule); err != nil {
+			iter.Close()
+			return n, rule.ID, err
+		}
+	}
+	return n, rule.ID, iter.Close()
+}
+
+// Update is an upsert unless the updateIfExists option makes it a lightweight
+// transaction, which costs a Paxos round but fails on a missing id.
+func (t *CassandraTester) Update(ctx context.Context, rule conf.ExperimentRule) error {
+	q := t.session.Query(t.q.update, rule.ExperimentName, rule.TargetingRules, rule.ID).WithContext(ctx)
+	if !t.updateIfExists {
+		return q.Exec()
+	}
+	applied, err := q.MapScanCAS(map[string]any{})
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return workload.ErrNotFound
+	}
+	return nil
+}
+
+func (t *CassandraTester) Insert(ctx context.Context, rule conf.ExperimentRule) error {
+	return t.session.Query(t.q.insert, rule.ID, rule.ExperimentName, rule.TargetingRules).WithContext(ctx).Exec()
+}
+
+func (t *CassandraTester) Delete(ctx context.Context, id int64) error {
+	return t.session.Query(t.q.delete, id).WithContext(ctx).Exec()
+}
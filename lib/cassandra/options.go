@@ -0,0 +1,69 @@
+package cassandra
+
+import (
+	"fmt"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+
+	"github.com/gocql/gocql"
+)
+
+var driverOptions = []backend.ConfigKey{
+	{Key: "timeout", Description: "query timeout (default 20s)"},
+	{Key: "connectTimeout", Description: "dial timeout (default connect_timeout)"},
+	{Key: "numConns", Description: "connections per host (default 2)"},
+	{Key: "compression", Description: "none (default) or snappy: compressed native protocol"},
+	{Key: "loadBalancing", Description: "roundRobin (default) or tokenAware"},
+	{Key: "localDC", Description: "prefer hosts of this datacenter"},
+	{Key: "retries", Description: "retries of a failed query (default 0)"},
+	{Key: "keepalive", Description: "TCP keepalive period (default off)"},
+	{Key: "seedConcurrency", Description: "concurrent inserts per seed batch (default 64)"},
+	{Key: "updateIfExists", Description: "true: updates use IF EXISTS and fail on a missing id (default false: upsert)"},
+	{Key: "bootstrapKeyspace", Description: "keyspace the first session opens to create dbName (default system)"},
+}
+
+// applyOptions maps cassandra.options onto the cluster config.
+func applyOptions(cluster *gocql.ClusterConfig, o conf.Settings) error {
+	var err error
+	if cluster.Timeout, err = o.Duration("timeout", cluster.Timeout); err != nil {
+		return err
+	}
+	if cluster.ConnectTimeout, err = o.Duration("connectTimeout", cluster.ConnectTimeout); err != nil {
+		return err
+	}
+	if cluster.NumConns, err = o.Int("numConns", cluster.NumConns); err != nil {
+		return err
+	}
+	if cluster.SocketKeepalive, err = o.Duration("keepalive", cluster.SocketKeepalive); err != nil {
+		return err
+	}
+	retries, err := o.Int("retries", 0)
+	if err != nil {
+		return err
+	}
+	if retries > 0 {
+		cluster.RetryPolicy = &gocql.SimpleRetryPolicy{NumRetries: retries}
+	}
+	switch c := o.Setting("compression", "none"); c {
+	case "none":
+	case "snappy":
+		cluster.Compressor = gocql.SnappyCompressor{}
+	default:
+		return fmt.Errorf("unknown compression %q (want none or snappy)", c)
+	}
+
+	policy := gocql.RoundRobinHostPolicy()
+	if dc := o.Setting("localDC", ""); dc != "" {
+		policy = gocql.DCAwareRoundRobinPolicy(dc)
+	}
+	switch lb := o.Setting("loadBalancing", "roundRobin"); lb {
+	case "roundRobin":
+		cluster.PoolConfig.HostSelectionPolicy = policy
+	case "tokenAware":
+		cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(policy)
+	default:
+		return fmt.Errorf("unknown loadBalancing %q (want tokenAware or roundRobin)", lb)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package cassandra
+
+import (
+	"context"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+)
+
+func init() {
+	backend.Register(backend.Backend{
+		Name: "cassandra",
+		New: func(ctx context.Context, cfg *conf.Config) (backend.Tester, error) {
+			t, err := NewCassandraTester(ctx, cfg)
+			if err != nil {
+				return nil, err
+			}
+			return t, nil
+		},
+		Config:       backend.CommonConfig,
+		Capabilities: backend.CapBatchSeed,
+		Schema:       schemaSettings,
+		Options:      driverOptions,
+		Consistency:  consistencyLevels,
+	})
+}
@@ -0,0 +1,65 @@
+package cassandra
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+)
+
+var schemaSettings = []backend.ConfigKey{
+	{Key: "compaction", Description: "compaction strategy: SizeTiered (default), Leveled, TimeWindow or Unified"},
+	{Key: "compression", Description: "SSTable compression: lz4 (default), zstd, snappy, deflate or none"},
+	{Key: "chunkLengthKB", Description: "compression chunk length in KB, a power of 2 (default 16)"},
+}
+
+var (
+	compactions  = []string{"SizeTiered", "Leveled", "TimeWindow", "Unified"}
+	compressions = map[string]string{
+		"lz4": "LZ4Compressor", "zstd": "ZstdCompressor", "snappy": "SnappyCompressor", "deflate": "DeflateCompressor",
+	}
+)
+
+// tableOptions builds the WITH clause of CREATE TABLE for the schema variant,
+// or "" for the defaults.
+func tableOptions(s conf.Schema) (string, error) {
+	var opts []string
+	if c := s.Setting("compaction", ""); c != "" {
+		name := strings.TrimSuffix(c, "CompactionStrategy")
+		i := slices.IndexFunc(compactions, func(s string) bool { return strings.EqualFold(s, name) })
+		if i < 0 {
+			return "", fmt.Errorf("unknown compaction %q (want SizeTiered, Leveled, TimeWindow or Unified)", c)
+		}
+		opts = append(opts, fmt.Sprintf("compaction = {'class': '%sCompactionStrategy'}", compactions[i]))
+	}
+	chunk, err := s.Int("chunkLengthKB", 0)
+	if err != nil {
+		return "", err
+	}
+	if chunk < 0 || chunk&(chunk-1) != 0 {
+		return "", fmt.Errorf("chunkLengthKB must be a power of 2")
+	}
+	switch c := strings.ToLower(s.Setting("compression", "")); c {
+	case "":
+		if chunk != 0 {
+			opts = append(opts, fmt.Sprintf("compression = {'class': 'LZ4Compressor', 'chunk_length_in_kb': %d}", chunk))
+		}
+	case "none":
+		opts = append(opts, "compression = {'enabled': 'false'}")
+	default:
+		class, ok := compressions[c]
+		if !ok {
+			return "", fmt.Errorf("unknown compression %q (want lz4, zstd, snappy, deflate or none)", c)
+		}
+		if chunk == 0 {
+			chunk = 16
+		}
+		opts = append(opts, fmt.Sprintf("compression = {'class': '%s', 'chunk_length_in_kb': %d}", class, chunk))
+	}
+	if len(opts) == 0 {
+		return "", nil
+	}
+	return " WITH " + strings.Join(opts, " AND "), nil
+}
@@ -3,29 +3,111 @@ package cassandra
 import (
 	"context"
 	"db-bench/lib/conf"
+	"db-bench/lib/seed"
+	"db-bench/lib/workload"
+	"errors"
 	"fmt"
-	"log"
+	"sync"
+
+	"github.com/gocql/gocql"
 )
 
-func (t *CassandraTester) Seed(_ context.Context) error {
+func (t *CassandraTester) Seed(ctx context.Context) error {
 	err := t.session.Query(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
 		id bigint PRIMARY KEY,
 		experiment_name text,
 		targeting_rules text
-	)`, t.cfg.TableName)).Exec()
+	)%s`, t.cfg.TableName, t.tableOptions)).WithContext(ctx).Exec()
 	if err != nil {
 		return fmt.Errorf("failed to create table in cassandra: %w", err)
 	}
-	log.Println("Cassandra: Writing rows...")
+	concurrency, err := t.cfg.Options.Int("seedConcurrency", 64)
+	if err != nil {
+		return fmt.Errorf("cassandra.options: %w", err)
+	}
+	if concurrency < 1 {
+		return fmt.Errorf("cassandra.options: seedConcurrency must be at least 1")
+	}
 	query := fmt.Sprintf("INSERT INTO %s (id, experiment_name, targeting_rules) VALUES (?, ?, ?)", t.cfg.TableName)
-	for i := 1; i <= t.cfg.RecordCount; i++ {
-		rule := conf.ExperimentRule{ID: int64(i), ExperimentName: fmt.Sprintf("Test %d", i), TargetingRules: `{"country":"US"}`}
-		if err := t.session.Query(query, rule.ID, rule.ExperimentName, rule.TargetingRules).Exec(); err != nil {
-			log.Printf("Warning: Cassandra insert failed for key %d: %v", i, err)
-		}
-		if i%10000 == 0 {
-			log.Printf("Cassandra: %d records inserted...", i)
+	// Every id is its own partition, so instead of multi-partition batches
+	// (which overload the coordinator) the rows of a batch are written
+	// concurrently, at most seedConcurrency at a time per seed worker.
+	return seed.Run(ctx, "Cassandra", t.cfg, t, func(ctx context.Context, rows []conf.ExperimentRule) error {
+		var (
+			wg       sync.WaitGroup
+			once     sync.Once
+			firstErr error
+		)
+		fail := func(err error) { once.Do(func() { firstErr = err }) }
+		sem := make(chan struct{}, concurrency)
+	spawn:
+		for _, rule := range rows {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				fail(ctx.Err())
+				break spawn
+			}
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				err := t.session.Query(query, rule.ID, rule.ExperimentName, rule.TargetingRules).WithContext(ctx).Exec()
+				if err != nil {
+					fail(fmt.Errorf("key %d: %w", rule.ID, err))
+				}
+			}()
 		}
+		wg.Wait()
+		return firstErr
+	})
+}
+
+func (t *CassandraTester) Count(ctx context.Context) (int64, error) {
+	n, _, err := t.scanIDs(ctx)
+	return n, err
+}
+
+func (t *CassandraTester) MaxID(ctx context.Context) (int64, error) {
+	_, maxID, err := t.scanIDs(ctx)
+	return maxID, err
+}
+
+// scanIDs pages through every id of the table: a single COUNT(*) or MAX(id)
+// over a whole table times out on any realistic dataset.
+func (t *CassandraTester) scanIDs(ctx context.Context) (n, maxID int64, err error) {
+	iter := t.session.Query(fmt.Sprintf("SELECT id FROM %s", t.cfg.TableName)).WithContext(ctx).PageSize(5000).Iter()
+	var id int64
+	for iter.Scan(&id) {
+		n++
+		maxID = max(maxID, id)
+	}
+	return n, maxID, iter.Close()
+}
+
+func (t *CassandraTester) Get(ctx context.Context, id int64) (conf.ExperimentRule, error) {
+	rule := conf.ExperimentRule{ID: id}
+	err := t.session.Query(t.q.get, id).WithContext(ctx).Consistency(t.consistency).
+		Scan(&rule.ExperimentName, &rule.TargetingRules)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return rule, workload.ErrNotFound
+	}
+	return rule, err
+}
+
+// Drop removes the table and then the keyspace NewCassandraTester created,
+// unless other tables still live in it.
+func (t *CassandraTester) Drop(ctx context.Context) error {
+	if err := t.session.Query(fmt.Sprintf("DROP TABLE IF EXISTS %s", t.cfg.TableName)).WithContext(ctx).Exec(); err != nil {
+		return err
+	}
+	var tables int
+	err := t.session.Query("SELECT COUNT(*) FROM system_schema.tables WHERE keyspace_name = ?", t.cfg.DBName).
+		WithContext(ctx).Scan(&tables)
+	if err != nil {
+		return err
+	}
+	if tables > 0 {
+		return nil
 	}
-	return nil
+	return t.session.Query(fmt.Sprintf("DROP KEYSPACE IF EXISTS %s", t.cfg.DBName)).WithContext(ctx).Exec()
 }
@@ -12,15 +12,32 @@ import (
 type CassandraTester struct {
 	session *gocql.Session
 	cfg     *conf.Config
+	q       queries
+	// WITH clause of CREATE TABLE for the schema variant
+	tableOptions string
+	// consistency of reads and writes
+	consistency gocql.Consistency
+	// updates are lightweight transactions with IF EXISTS
+	updateIfExists bool
 }
 
 func NewCassandraTester(ctx context.Context, cfg *conf.Config) (*CassandraTester, error) {
+	opts, err := tableOptions(cfg.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("cassandra.schemas.%s: %w", cfg.Schema.Name, err)
+	}
+	updateIfExists, err := cfg.Options.Bool("updateIfExists", false)
+	if err != nil {
+		return nil, fmt.Errorf("cassandra.options: %w", err)
+	}
 	cluster := gocql.NewCluster(cfg.URI)
-	cluster.Keyspace = "system"
+	cluster.Keyspace = cfg.Options.Setting("bootstrapKeyspace", "system")
 	cluster.Timeout = 20 * time.Second
 	cluster.ConnectTimeout = cfg.ConnectTimeout
+	if err := applyOptions(cluster, cfg.Options); err != nil {
+		return nil, fmt.Errorf("cassandra.options: %w", err)
+	}
 	var session *gocql.Session
-	var err error
 	for i := 0; i < 5; i++ {
 		session, err = cluster.CreateSession()
 		if err == nil {
@@ -40,11 +57,15 @@ func NewCassandraTester(ctx context.Context, cfg *conf.Config) (*CassandraTester
 		return nil, err
 	}
 	cluster.Keyspace = cfg.DBName
+	// Writes run at the read level too, so that every level of a matrix
+	// describes the whole workload rather than reads next to QUORUM writes.
+	cluster.Consistency = sessionConsistency[cfg.Consistency]
 	finalSession, err := cluster.CreateSession()
 	if err != nil {
 		return nil, err
 	}
-	return &CassandraTester{session: finalSession, cfg: cfg}, nil
+	return &CassandraTester{session: finalSession, cfg: cfg, q: newQueries(cfg.TableName, updateIfExists),
+		tableOptions: opts, consistency: cluster.Consistency, updateIfExists: updateIfExists}, nil
 }
 
 func (t *CassandraTester) Close() { t.session.Close() }
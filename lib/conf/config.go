@@ -2,6 +2,11 @@ package conf
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -10,17 +15,562 @@ import (
 )
 
 type Config struct {
-	DB              string
-	URI             string
-	DBName          string
-	WorkerCount     int
-	RecordCount     int
-	TableName       string
-	TestDuration    time.Duration
-	ConnectTimeout  time.Duration
-	ReadsTotal      *prometheus.CounterVec
-	ReadErrorsTotal *prometheus.CounterVec
-	ReadLatency     *prometheus.HistogramVec
+	DB                     string                   `json:"db"`
+	URI                    string                   `json:"uri"`
+	DBName                 string                   `json:"dbName"`
+	WorkerCount            int                      `json:"workerCount"`
+	RecordCount            int                      `json:"recordCount"`
+	TableName              string                   `json:"tableName"`
+	TestDuration           time.Duration            `json:"testDuration"`
+	ConnectTimeout         time.Duration            `json:"connectTimeout"`
+	KeyDistribution        KeyDistribution          `json:"keyDistribution"`
+	Workload               Workload                 `json:"workload"`
+	Rate                   Rate                     `json:"rate"`
+	Phases                 Phases                   `json:"phases"`
+	Profile                Profile                  `json:"profile"`
+	FindMax                FindMax                  `json:"findMax"`
+	Matrix                 Matrix                   `json:"matrix"`
+	Seed                   Seed                     `json:"seed"`
+	Payload                Payload                  `json:"payload"`
+	Read                   Read                     `json:"read"`
+	Scan                   Scan                     `json:"scan"`
+	Query                  Query                    `json:"query"`
+	Schema                 Schema                   `json:"schema"`
+	Options                Settings                 `json:"options,omitempty"`
+	Consistency            string                   `json:"consistency,omitempty"`
+	Report                 Report                   `json:"report"`
+	Latency                Latency                  `json:"latency"`
+	Metrics                Metrics                  `json:"metrics"`
+	ReadsTotal             *prometheus.CounterVec   `json:"-"`
+	ReadErrorsTotal        *prometheus.CounterVec   `json:"-"`
+	ReadLatency            *prometheus.HistogramVec `json:"-"`
+	ActiveWorkers          *prometheus.GaugeVec     `json:"-"`
+	ReadValidationFailures *prometheus.CounterVec   `json:"-"`
+}
+
+// Phases задаёт прогрев и остывание вокруг фазы измерения (TestDuration).
+// В отчёт попадают только операции фазы измерения.
+type Phases struct {
+	Warmup   time.Duration `json:"warmup"`
+	Cooldown time.Duration `json:"cooldown"`
+	// сколько ждать завершения операций в полёте после остановки прогона
+	DrainTimeout time.Duration `json:"drainTimeout"`
+}
+
+// Profile меняет нагрузку по ходу фазы измерения. Если задан, длительность
+// измерения равна сумме длительностей стадий, а TestDuration перезаписывается.
+type Profile struct {
+	Stages []Stage `json:"stages"`
+	Ramp   Ramp    `json:"ramp"`
+}
+
+// Stage — отрезок прогона с постоянной нагрузкой.
+type Stage struct {
+	Duration time.Duration `json:"duration" mapstructure:"duration"`
+	// 0 — WorkerCount
+	Workers int `json:"workers" mapstructure:"workers"`
+	// 0 — closed loop
+	TargetOpsPerSec float64 `json:"targetOpsPerSec" mapstructure:"targetOpsPerSec"`
+}
+
+// Ramp генерирует Steps стадий по StepDuration, равномерно меняя
+// число воркеров (By = "workers") или целевой rate (By = "rate") от From до To.
+type Ramp struct {
+	By           string        `json:"by"`
+	From         float64       `json:"from"`
+	To           float64       `json:"to"`
+	Steps        int           `json:"steps"`
+	StepDuration time.Duration `json:"stepDuration"`
+}
+
+func (r Ramp) stages(workerCount int) ([]Stage, error) {
+	if r.Steps < 1 || r.StepDuration <= 0 || r.From <= 0 || r.To <= 0 {
+		return nil, fmt.Errorf("profile.ramp needs from, to > 0, steps >= 1 and stepDuration > 0")
+	}
+	stages := make([]Stage, r.Steps)
+	for i := range stages {
+		v := r.From
+		if r.Steps > 1 {
+			v += (r.To - r.From) * float64(i) / float64(r.Steps-1)
+		}
+		stages[i] = Stage{Duration: r.StepDuration, Workers: workerCount}
+		switch r.By {
+		case "workers":
+			stages[i].Workers = int(v + 0.5)
+		case "rate":
+			stages[i].TargetOpsPerSec = v
+		default:
+			return nil, fmt.Errorf("profile.ramp.by must be workers or rate, got %q", r.By)
+		}
+	}
+	return stages, nil
+}
+
+// FindMax задаёт поиск максимального rate, при котором выполняется SLO
+// (команда find-max): rate удваивается от MinOpsPerSec до первого провала,
+// затем делится пополам между последним успехом и первым провалом.
+type FindMax struct {
+	SLO          SLO     `json:"slo"`
+	MinOpsPerSec float64 `json:"minOpsPerSec"`
+	MaxOpsPerSec float64 `json:"maxOpsPerSec"`
+	// длительность измерения одной попытки
+	TrialDuration time.Duration `json:"trialDuration"`
+	// прогрев перед каждой попыткой, кроме первой (для неё — phases.warmup)
+	Settle time.Duration `json:"settle"`
+	// поиск останавливается, когда (провал - успех) / успех <= Precision
+	Precision float64 `json:"precision"`
+	MaxTrials int     `json:"maxTrials"`
+}
+
+// SLO — условия, при которых попытка find-max считается успешной.
+type SLO struct {
+	P99       time.Duration `json:"p99"`
+	ErrorRate float64       `json:"errorRate"`
+	// какую долю целевого rate база должна реально выдать
+	MinThroughputRatio float64 `json:"minThroughputRatio"`
+}
+
+func (f FindMax) validate() error {
+	switch {
+	case f.MinOpsPerSec <= 0 || f.MaxOpsPerSec < f.MinOpsPerSec:
+		return fmt.Errorf("findMax needs 0 < minOpsPerSec <= maxOpsPerSec")
+	case f.TrialDuration <= 0 || f.Settle < 0:
+		return fmt.Errorf("findMax.trialDuration must be positive and findMax.settle not negative")
+	case f.Precision <= 0 || f.MaxTrials < 1:
+		return fmt.Errorf("findMax needs precision > 0 and maxTrials >= 1")
+	case f.SLO.P99 <= 0 || f.SLO.ErrorRate < 0 || f.SLO.MinThroughputRatio < 0 || f.SLO.MinThroughputRatio > 1:
+		return fmt.Errorf("findMax.slo needs p99 > 0, errorRate >= 0 and minThroughputRatio in [0, 1]")
+	}
+	return nil
+}
+
+// Matrix задаёт уровни согласованности, которые dbbench matrix прогоняет по
+// очереди с одной и той же нагрузкой; пусто — все уровни базы.
+type Matrix struct {
+	Levels []string `json:"levels,omitempty"`
+}
+
+// Seed задаёт, как dbbench seed заливает RecordCount строк: пачками по
+// BatchSize из Workers горутин. Секция <db>.seed перекрывает общую.
+type Seed struct {
+	BatchSize int `json:"batchSize"`
+	Workers   int `json:"workers"`
+	// как часто писать в лог прогресс (строк/с и ETA)
+	ProgressInterval time.Duration `json:"progressInterval"`
+	// сколько раз повторять упавшую пачку; пауза растёт вдвое от RetryBackoff
+	Retries      int           `json:"retries"`
+	RetryBackoff time.Duration `json:"retryBackoff"`
+	// продолжить с максимального id, уже лежащего в базе
+	Resume bool `json:"resume"`
+	// сколько случайных строк сверяет dbbench verify
+	VerifySamples int `json:"verifySamples"`
+}
+
+// Генераторы строк ExperimentRule
+const (
+	// "Test <id>" и {"country":"US"}
+	PayloadMinimal = "minimal"
+	// вложенные правила таргетинга заданного размера
+	PayloadRealistic = "realistic"
+)
+
+// Payload задаёт генератор строк ExperimentRule. Строка зависит только от id
+// и этих настроек, поэтому данные во всех базах совпадают.
+type Payload struct {
+	Generator string `json:"generator"`
+	Seed      int64  `json:"seed"`
+	// распределение размера targeting_rules в байтах
+	Size PayloadSize `json:"size"`
+	// максимальная вложенность групп условий (and/or)
+	MaxDepth      int `json:"maxDepth"`
+	MinConditions int `json:"minConditions"`
+	MaxConditions int `json:"maxConditions"`
+	// сколько различных значений у каждого атрибута условий
+	Cardinality int `json:"cardinality"`
+	// сколько различных experiment_name; 0 — у каждой строки своё
+	NameCardinality int `json:"nameCardinality"`
+}
+
+// PayloadSize — распределение размера: fixed (Mean), uniform (Min..Max)
+// или normal (Mean, StdDev), обрезанное по Min..Max.
+type PayloadSize struct {
+	Dist   string `json:"dist"`
+	Min    int    `json:"min"`
+	Max    int    `json:"max"`
+	Mean   int    `json:"mean"`
+	StdDev int    `json:"stdDev"`
+}
+
+func (p Payload) validate() error {
+	switch p.Generator {
+	case PayloadMinimal:
+		return nil
+	case PayloadRealistic:
+	default:
+		return fmt.Errorf("unknown payload.generator %q (want minimal or realistic)", p.Generator)
+	}
+	switch p.Size.Dist {
+	case "fixed", "uniform", "normal":
+	default:
+		return fmt.Errorf("unknown payload.size.dist %q (want fixed, uniform or normal)", p.Size.Dist)
+	}
+	switch {
+	case p.Size.Min < 0 || p.Size.Max < p.Size.Min || p.Size.StdDev < 0:
+		return fmt.Errorf("payload.size needs 0 <= min <= max and stdDev >= 0")
+	case p.MaxDepth < 1:
+		return fmt.Errorf("payload.maxDepth must be at least 1")
+	case p.MinConditions < 1 || p.MaxConditions < p.MinConditions:
+		return fmt.Errorf("payload needs 1 <= minConditions <= maxConditions")
+	case p.Cardinality < 1 || p.NameCardinality < 0:
+		return fmt.Errorf("payload.cardinality must be positive and payload.nameCardinality not negative")
+	}
+	return nil
+}
+
+// Что читает операция read
+const (
+	// только первичный ключ (может обслуживаться одним индексом)
+	ProjectionID = "id"
+	// вся строка
+	ProjectionRow = "row"
+	// вся строка и разбор JSON из targeting_rules
+	ProjectionDecode = "decode"
+)
+
+// Read задаёт одинаковую для всех баз работу операции read.
+type Read struct {
+	Projection string `json:"projection"`
+	// доля чтений, сверяемых с генератором payload; 0 — без проверки
+	Validate float64 `json:"validate"`
+	// сколько расхождений попадает в отчёт примерами
+	ValidationExamples int      `json:"validationExamples"`
+	MultiGet           MultiGet `json:"multiGet"`
+}
+
+// Как выполняется multi-get
+const (
+	// одним запросом средствами базы (IN, ANY, $in, Txn, список параметров)
+	MultiGetNative = "native"
+	// параллельными одиночными чтениями
+	MultiGetParallel = "parallel"
+)
+
+// MultiGet задаёт операцию чтения нескольких строк сразу.
+type MultiGet struct {
+	// сколько различных ключей читается за раз
+	BatchSize int    `json:"batchSize"`
+	Mode      string `json:"mode"`
+}
+
+// Способы листать таблицу
+const (
+	// диапазон id: WHERE id >= start AND id < start + length
+	ScanRange = "range"
+	// keyset-пагинация: WHERE id > last ORDER BY id LIMIT length
+	ScanKeyset = "keyset"
+	// ORDER BY id LIMIT length OFFSET n
+	ScanOffset = "offset"
+)
+
+// Scan задаёт операцию scan: одна операция читает Pages страниц по длине
+// из LengthDist, начиная с ключа из keyDistribution.
+type Scan struct {
+	Mode string `json:"mode"`
+	// fixed — всегда MaxLength, uniform — от 1 до MaxLength (как в YCSB E)
+	LengthDist string `json:"lengthDist"`
+	MaxLength  int    `json:"maxLength"`
+	Pages      int    `json:"pages"`
+}
+
+func (s Scan) validate() error {
+	switch s.Mode {
+	case ScanRange, ScanKeyset, ScanOffset:
+	default:
+		return fmt.Errorf("unknown scan.mode %q (want range, keyset or offset)", s.Mode)
+	}
+	if s.LengthDist != "fixed" && s.LengthDist != "uniform" {
+		return fmt.Errorf("unknown scan.lengthDist %q (want fixed or uniform)", s.LengthDist)
+	}
+	// YDB отдаёт не больше 1000 строк в одном результате
+	if s.MaxLength < 1 || s.MaxLength > 1000 {
+		return fmt.Errorf("scan.maxLength must be between 1 and 1000")
+	}
+	if s.Pages < 1 {
+		return fmt.Errorf("scan.pages must be positive")
+	}
+	return nil
+}
+
+// Query задаёт операцию query: поиск экспериментов, у которых атрибут
+// targeting_rules равен значению из строки случайного ключа.
+type Query struct {
+	// путь к атрибуту через точку, например traffic.layer
+	Attribute string `json:"attribute"`
+	// сколько строк возвращает один запрос
+	Limit int `json:"limit"`
+	// true — seed создаёт индекс под атрибут (GIN, generated column, индекс по полю)
+	Index bool `json:"index"`
+}
+
+// Path возвращает путь к атрибуту по сегментам.
+func (q Query) Path() []string {
+	return strings.Split(q.Attribute, ".")
+}
+
+func (q Query) validate() error {
+	for _, seg := range q.Path() {
+		if !identifier.MatchString(seg) {
+			return fmt.Errorf("invalid query.attribute %q (want dot-separated identifiers)", q.Attribute)
+		}
+	}
+	if q.Limit < 1 || q.Limit > 1000 {
+		return fmt.Errorf("query.limit must be between 1 and 1000")
+	}
+	return nil
+}
+
+// identifier — сегмент пути, который можно подставить в текст запроса.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Schema — вариант схемы из <db>.schemas, который создаёт seed. Набор
+// настроек у каждой базы свой, см. dbbench list -v; пустое имя — схема по
+// умолчанию. Прогон должен идти с тем же вариантом, что и seed.
+type Schema struct {
+	Name     string `json:"name,omitempty"`
+	Settings `json:"settings,omitempty"`
+}
+
+// String — имя варианта и его настройки, для отчёта.
+func (s Schema) String() string {
+	return fmt.Sprintf("%s (%s)", s.Name, s.Settings)
+}
+
+// Settings — настройки конкретной базы (<db>.schemas.<name>, <db>.options);
+// допустимые ключи перечисляет backend.
+type Settings map[string]string
+
+func (s Settings) String() string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = k + "=" + s[k]
+	}
+	return strings.Join(keys, ", ")
+}
+
+// Setting возвращает настройку key или def, если она не задана.
+func (s Settings) Setting(key, def string) string {
+	if v, ok := s[key]; ok {
+		return v
+	}
+	return def
+}
+
+// Int возвращает целочисленную настройку key или def, если она не задана.
+func (s Settings) Int(key string, def int) (int, error) {
+	v, ok := s[key]
+	if !ok {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not an integer", key, v)
+	}
+	return n, nil
+}
+
+// Bool возвращает логическую настройку key или def, если она не задана.
+func (s Settings) Bool(key string, def bool) (bool, error) {
+	v, ok := s[key]
+	if !ok {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s: %q is not a boolean", key, v)
+	}
+	return b, nil
+}
+
+// Duration возвращает настройку-длительность key или def, если она не задана.
+func (s Settings) Duration(key string, def time.Duration) (time.Duration, error) {
+	v, ok := s[key]
+	if !ok {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a duration", key, v)
+	}
+	return d, nil
+}
+
+// List возвращает настройку key, разбитую по запятым, или nil, если она не задана.
+func (s Settings) List(key string) []string {
+	v, ok := s[key]
+	if !ok {
+		return nil
+	}
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Report задаёт, куда и в каких форматах писать итоговый отчёт.
+type Report struct {
+	Dir     string   `json:"dir"`
+	Formats []string `json:"formats"`
+}
+
+// Latency задаёт точность HDR-гистограмм, по которым считаются перцентили отчёта.
+type Latency struct {
+	// 1..5 значащих цифр; 3 — погрешность 0.1%
+	SignificantFigures int `json:"significantFigures"`
+	// задержки больше MaxTrackable записываются как MaxTrackable
+	MaxTrackable time.Duration `json:"maxTrackable"`
+}
+
+// Metrics задаёт раскладку бакетов ab_read_latency_seconds.
+type Metrics struct {
+	// границы бакетов в секундах
+	Buckets []float64 `json:"buckets"`
+	// >1 — дополнительно отдавать native histogram с таким фактором роста бакетов
+	NativeBucketFactor float64 `json:"nativeBucketFactor"`
+}
+
+var reportFormats = map[string]bool{"json": true, "csv": true, "md": true}
+
+// Snapshot возвращает копию конфига для отчёта с вырезанным паролем из URI.
+func (c *Config) Snapshot() Config {
+	snap := *c
+	snap.URI = RedactURI(c.URI)
+	return snap
+}
+
+var uriPassword = regexp.MustCompile(`(^|//)([^:/@]+):([^@/]*)@`)
+
+// RedactURI заменяет пароль в URI или DSN (user:password@...) на "***".
+func RedactURI(uri string) string {
+	return uriPassword.ReplaceAllString(uri, "${1}${2}:***@")
+}
+
+// Распределения ключей для чтения
+const (
+	DistUniform     = "uniform"
+	DistZipfian     = "zipfian"
+	DistHotspot     = "hotspot"
+	DistLatest      = "latest"
+	DistSequential  = "sequential"
+	DistExponential = "exponential"
+)
+
+// KeyDistribution задаёт, какие id выбирают воркеры.
+type KeyDistribution struct {
+	Type string `json:"type"`
+	// zipfian, latest: 0 < theta < 1, чем больше, тем сильнее перекос
+	ZipfianTheta float64 `json:"zipfianTheta"`
+	// zipfian: разбросать горячие ключи по всему диапазону
+	Scrambled bool `json:"scrambled"`
+	// hotspot: HotspotOpFraction операций приходится на HotspotDataFraction ключей
+	HotspotDataFraction float64 `json:"hotspotDataFraction"`
+	HotspotOpFraction   float64 `json:"hotspotOpFraction"`
+	// exponential: ExponentialPercentile% операций на последние ExponentialFraction ключей
+	ExponentialPercentile float64 `json:"exponentialPercentile"`
+	ExponentialFraction   float64 `json:"exponentialFraction"`
+	// 0 — случайный seed на каждый запуск
+	Seed int64 `json:"seed"`
+}
+
+func (d KeyDistribution) validate() error {
+	switch d.Type {
+	case DistUniform, DistSequential:
+	case DistZipfian, DistLatest:
+		if d.ZipfianTheta <= 0 || d.ZipfianTheta >= 1 {
+			return fmt.Errorf("keyDistribution.zipfianTheta must be in (0, 1), got %v", d.ZipfianTheta)
+		}
+	case DistHotspot:
+		if d.HotspotDataFraction <= 0 || d.HotspotDataFraction > 1 ||
+			d.HotspotOpFraction < 0 || d.HotspotOpFraction > 1 {
+			return fmt.Errorf("keyDistribution.hotspot fractions must be in (0, 1]")
+		}
+	case DistExponential:
+		if d.ExponentialPercentile <= 0 || d.ExponentialPercentile >= 100 || d.ExponentialFraction <= 0 {
+			return fmt.Errorf("keyDistribution.exponentialPercentile must be in (0, 100) and exponentialFraction > 0")
+		}
+	default:
+		return fmt.Errorf("unknown keyDistribution.type %q", d.Type)
+	}
+	return nil
+}
+
+// Workload задаёт доли операций; доли не обязаны давать в сумме 1.
+type Workload struct {
+	Preset          string  `json:"preset"`
+	Read            float64 `json:"read"`
+	Update          float64 `json:"update"`
+	Insert          float64 `json:"insert"`
+	Delete          float64 `json:"delete"`
+	ReadModifyWrite float64 `json:"readModifyWrite"`
+	// чтение нескольких строк одним запросом, см. Read.MultiGet
+	MultiGet float64 `json:"multiGet"`
+	// чтение страниц подряд идущих строк, см. Scan
+	Scan float64 `json:"scan"`
+	// поиск по атрибуту targeting_rules, см. Query
+	Query float64 `json:"query"`
+}
+
+// Writes сообщает, меняет ли нагрузка данные.
+func (w Workload) Writes() bool {
+	return w.Update > 0 || w.Insert > 0 || w.Delete > 0 || w.ReadModifyWrite > 0
+}
+
+// Пресеты YCSB. D стоит запускать с keyDistribution.type = latest, E — с zipfian.
+var workloadPresets = map[string]Workload{
+	"a": {Read: 0.5, Update: 0.5},
+	"b": {Read: 0.95, Update: 0.05},
+	"c": {Read: 1},
+	"d": {Read: 0.95, Insert: 0.05},
+	"e": {Scan: 0.95, Insert: 0.05},
+	"f": {Read: 0.5, ReadModifyWrite: 0.5},
+}
+
+func (w Workload) validate() error {
+	for _, p := range []float64{w.Read, w.Update, w.Insert, w.Delete, w.ReadModifyWrite, w.MultiGet, w.Scan, w.Query} {
+		if p < 0 {
+			return fmt.Errorf("workload proportions must not be negative")
+		}
+	}
+	if w.Read+w.Update+w.Insert+w.Delete+w.ReadModifyWrite+w.MultiGet+w.Scan+w.Query == 0 {
+		return fmt.Errorf("workload has no operations")
+	}
+	return nil
+}
+
+// Rate включает open-loop режим: операции выдаются по расписанию с заданной
+// частотой, а задержка считается от запланированного времени старта.
+type Rate struct {
+	// 0 — closed loop, каждый воркер работает на максимальной скорости
+	TargetOpsPerSec float64 `json:"targetOpsPerSec"`
+	// true — TargetOpsPerSec задаётся на каждого воркера, а не на весь прогон
+	PerWorker bool `json:"perWorker"`
+}
+
+func (r Rate) String() string {
+	switch {
+	case r.TargetOpsPerSec <= 0:
+		return "closed loop"
+	case r.PerWorker:
+		return fmt.Sprintf("open loop %.0f ops/s per worker", r.TargetOpsPerSec)
+	default:
+		return fmt.Sprintf("open loop %.0f ops/s", r.TargetOpsPerSec)
+	}
 }
 
 type ExperimentRule struct {
@@ -29,7 +579,9 @@ type ExperimentRule struct {
 	TargetingRules string `bson:"targeting_rules" json:"targeting_rules"`
 }
 
-func LoadConfig(db string, configPath string) (*Config, error) {
+// LoadConfig читает config.yaml и применяет overrides (ключи в формате viper,
+// например "workerCount" или "postgres.uri") поверх файла и переменных окружения.
+func LoadConfig(db string, configPath string, overrides map[string]any) (*Config, error) {
 	v := viper.New()
 	if configPath != "" {
 		v.SetConfigFile(configPath)
@@ -44,42 +596,423 @@ func LoadConfig(db string, configPath string) (*Config, error) {
 	v.SetDefault("tableName", "experiment_rules")
 	v.SetDefault("testDuration", "10m")
 	v.SetDefault("connectTimeout", "15s")
+	v.SetDefault("keyDistribution.type", DistUniform)
+	v.SetDefault("keyDistribution.zipfianTheta", 0.99)
+	v.SetDefault("keyDistribution.scrambled", false)
+	v.SetDefault("keyDistribution.hotspotDataFraction", 0.2)
+	v.SetDefault("keyDistribution.hotspotOpFraction", 0.8)
+	v.SetDefault("keyDistribution.exponentialPercentile", 95)
+	v.SetDefault("keyDistribution.exponentialFraction", 0.8571428571)
+	v.SetDefault("keyDistribution.seed", 0)
+	v.SetDefault("workload.preset", "")
+	v.SetDefault("workload.read", 1)
+	v.SetDefault("rate.targetOpsPerSec", 0)
+	v.SetDefault("rate.perWorker", false)
+	v.SetDefault("phases.warmup", "0s")
+	v.SetDefault("phases.cooldown", "0s")
+	v.SetDefault("phases.drainTimeout", "5s")
+	v.SetDefault("findMax.slo.p99", "10ms")
+	v.SetDefault("findMax.slo.errorRate", 0.001)
+	v.SetDefault("findMax.slo.minThroughputRatio", 0.95)
+	v.SetDefault("findMax.minOpsPerSec", 100)
+	v.SetDefault("findMax.maxOpsPerSec", 100000)
+	v.SetDefault("findMax.trialDuration", "30s")
+	v.SetDefault("findMax.settle", "5s")
+	v.SetDefault("findMax.precision", 0.05)
+	v.SetDefault("findMax.maxTrials", 20)
+	v.SetDefault("seed.batchSize", 1000)
+	v.SetDefault("seed.workers", 8)
+	v.SetDefault("seed.progressInterval", "5s")
+	v.SetDefault("seed.retries", 5)
+	v.SetDefault("seed.retryBackoff", "200ms")
+	v.SetDefault("seed.resume", false)
+	v.SetDefault("seed.verifySamples", 1000)
+	v.SetDefault("payload.generator", PayloadMinimal)
+	v.SetDefault("payload.seed", 1)
+	v.SetDefault("payload.size.dist", "normal")
+	v.SetDefault("payload.size.min", 1024)
+	v.SetDefault("payload.size.max", 20480)
+	v.SetDefault("payload.size.mean", 4096)
+	v.SetDefault("payload.size.stdDev", 2048)
+	v.SetDefault("payload.maxDepth", 3)
+	v.SetDefault("payload.minConditions", 2)
+	v.SetDefault("payload.maxConditions", 12)
+	v.SetDefault("payload.cardinality", 50)
+	v.SetDefault("payload.nameCardinality", 0)
+	v.SetDefault("read.projection", ProjectionRow)
+	v.SetDefault("read.validate", 0)
+	v.SetDefault("read.validationExamples", 10)
+	v.SetDefault("read.multiGet.batchSize", 10)
+	v.SetDefault("read.multiGet.mode", MultiGetNative)
+	v.SetDefault("scan.mode", ScanRange)
+	v.SetDefault("scan.lengthDist", "uniform")
+	v.SetDefault("scan.maxLength", 100)
+	v.SetDefault("scan.pages", 1)
+	v.SetDefault("query.limit", 100)
+	v.SetDefault("query.index", false)
+	v.SetDefault("report.dir", "reports")
+	v.SetDefault("report.formats", []string{"json", "csv", "md"})
+	v.SetDefault("latency.significantFigures", 3)
+	v.SetDefault("latency.maxTrackable", "1m")
+	// 100µs * 2^n: от 100µs до ~3.3s вместо DefBuckets, где первый бакет 5ms
+	v.SetDefault("metrics.exponentialBuckets.start", 0.0001)
+	v.SetDefault("metrics.exponentialBuckets.factor", 2)
+	v.SetDefault("metrics.exponentialBuckets.count", 16)
+	v.SetDefault("metrics.nativeBucketFactor", 0)
 
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, err
 		}
 	}
+	for key, value := range overrides {
+		v.Set(key, value)
+	}
 
 	// Получаем параметры для выбранной базы
 	uri := v.GetString(fmt.Sprintf("%s.uri", db))
 	dbName := v.GetString(fmt.Sprintf("%s.dbName", db))
 	workerCount := v.GetInt("workerCount")
 	recordCount := v.GetInt("recordCount")
+	if workerCount < 1 || recordCount < 1 {
+		return nil, fmt.Errorf("workerCount and recordCount must be at least 1")
+	}
 	tableName := v.GetString("tableName")
 	testDuration := v.GetDuration("testDuration")
 	connectTimeout := v.GetDuration("connectTimeout")
+	keyDistribution := KeyDistribution{
+		Type:                  v.GetString("keyDistribution.type"),
+		ZipfianTheta:          v.GetFloat64("keyDistribution.zipfianTheta"),
+		Scrambled:             v.GetBool("keyDistribution.scrambled"),
+		HotspotDataFraction:   v.GetFloat64("keyDistribution.hotspotDataFraction"),
+		HotspotOpFraction:     v.GetFloat64("keyDistribution.hotspotOpFraction"),
+		ExponentialPercentile: v.GetFloat64("keyDistribution.exponentialPercentile"),
+		ExponentialFraction:   v.GetFloat64("keyDistribution.exponentialFraction"),
+		Seed:                  v.GetInt64("keyDistribution.seed"),
+	}
+	if err := keyDistribution.validate(); err != nil {
+		return nil, err
+	}
+	workload := Workload{
+		Read:            v.GetFloat64("workload.read"),
+		Update:          v.GetFloat64("workload.update"),
+		Insert:          v.GetFloat64("workload.insert"),
+		Delete:          v.GetFloat64("workload.delete"),
+		ReadModifyWrite: v.GetFloat64("workload.readModifyWrite"),
+		MultiGet:        v.GetFloat64("workload.multiGet"),
+		Scan:            v.GetFloat64("workload.scan"),
+		Query:           v.GetFloat64("workload.query"),
+	}
+	if preset := strings.ToLower(v.GetString("workload.preset")); preset != "" {
+		p, ok := workloadPresets[preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown workload.preset %q", preset)
+		}
+		workload = p
+		workload.Preset = preset
+	}
+	if err := workload.validate(); err != nil {
+		return nil, err
+	}
+	rate := Rate{
+		TargetOpsPerSec: v.GetFloat64("rate.targetOpsPerSec"),
+		PerWorker:       v.GetBool("rate.perWorker"),
+	}
+	if rate.TargetOpsPerSec < 0 {
+		return nil, fmt.Errorf("rate.targetOpsPerSec must not be negative")
+	}
+	phases := Phases{
+		Warmup:       v.GetDuration("phases.warmup"),
+		Cooldown:     v.GetDuration("phases.cooldown"),
+		DrainTimeout: v.GetDuration("phases.drainTimeout"),
+	}
+	if phases.Warmup < 0 || phases.Cooldown < 0 || phases.DrainTimeout < 0 {
+		return nil, fmt.Errorf("phases durations must not be negative")
+	}
+	profile, err := loadProfile(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(profile.Stages) > 0 {
+		testDuration = 0
+		for _, st := range profile.Stages {
+			testDuration += st.Duration
+		}
+	}
+	findMax := FindMax{
+		SLO: SLO{
+			P99:                v.GetDuration("findMax.slo.p99"),
+			ErrorRate:          v.GetFloat64("findMax.slo.errorRate"),
+			MinThroughputRatio: v.GetFloat64("findMax.slo.minThroughputRatio"),
+		},
+		MinOpsPerSec:  v.GetFloat64("findMax.minOpsPerSec"),
+		MaxOpsPerSec:  v.GetFloat64("findMax.maxOpsPerSec"),
+		TrialDuration: v.GetDuration("findMax.trialDuration"),
+		Settle:        v.GetDuration("findMax.settle"),
+		Precision:     v.GetFloat64("findMax.precision"),
+		MaxTrials:     v.GetInt("findMax.maxTrials"),
+	}
+	if err := findMax.validate(); err != nil {
+		return nil, err
+	}
+	seed := Seed{
+		BatchSize:        v.GetInt("seed.batchSize"),
+		Workers:          v.GetInt("seed.workers"),
+		ProgressInterval: v.GetDuration("seed.progressInterval"),
+		Retries:          v.GetInt("seed.retries"),
+		RetryBackoff:     v.GetDuration("seed.retryBackoff"),
+		Resume:           v.GetBool("seed.resume"),
+		VerifySamples:    v.GetInt("seed.verifySamples"),
+	}
+	if key := db + ".seed.batchSize"; v.IsSet(key) {
+		seed.BatchSize = v.GetInt(key)
+	}
+	if key := db + ".seed.workers"; v.IsSet(key) {
+		seed.Workers = v.GetInt(key)
+	}
+	if seed.BatchSize < 1 || seed.Workers < 1 || seed.ProgressInterval <= 0 {
+		return nil, fmt.Errorf("seed needs batchSize >= 1, workers >= 1 and progressInterval > 0")
+	}
+	if seed.Retries < 0 || seed.RetryBackoff < 0 || seed.VerifySamples < 0 {
+		return nil, fmt.Errorf("seed.retries, seed.retryBackoff and seed.verifySamples must not be negative")
+	}
+	payload := Payload{
+		Generator: strings.ToLower(v.GetString("payload.generator")),
+		Seed:      v.GetInt64("payload.seed"),
+		Size: PayloadSize{
+			Dist:   v.GetString("payload.size.dist"),
+			Min:    v.GetInt("payload.size.min"),
+			Max:    v.GetInt("payload.size.max"),
+			Mean:   v.GetInt("payload.size.mean"),
+			StdDev: v.GetInt("payload.size.stdDev"),
+		},
+		MaxDepth:        v.GetInt("payload.maxDepth"),
+		MinConditions:   v.GetInt("payload.minConditions"),
+		MaxConditions:   v.GetInt("payload.maxConditions"),
+		Cardinality:     v.GetInt("payload.cardinality"),
+		NameCardinality: v.GetInt("payload.nameCardinality"),
+	}
+	if err := payload.validate(); err != nil {
+		return nil, err
+	}
+	read := Read{
+		Projection:         strings.ToLower(v.GetString("read.projection")),
+		Validate:           v.GetFloat64("read.validate"),
+		ValidationExamples: v.GetInt("read.validationExamples"),
+		MultiGet: MultiGet{
+			BatchSize: v.GetInt("read.multiGet.batchSize"),
+			Mode:      strings.ToLower(v.GetString("read.multiGet.mode")),
+		},
+	}
+	switch read.Projection {
+	case ProjectionID, ProjectionRow, ProjectionDecode:
+	default:
+		return nil, fmt.Errorf("unknown read.projection %q (want id, row or decode)", read.Projection)
+	}
+	if read.Validate < 0 || read.Validate > 1 {
+		return nil, fmt.Errorf("read.validate must be between 0 and 1, got %v", read.Validate)
+	}
+	if read.ValidationExamples < 0 {
+		return nil, fmt.Errorf("read.validationExamples must not be negative")
+	}
+	if read.MultiGet.BatchSize < 1 {
+		return nil, fmt.Errorf("read.multiGet.batchSize must be positive")
+	}
+	if read.MultiGet.Mode != MultiGetNative && read.MultiGet.Mode != MultiGetParallel {
+		return nil, fmt.Errorf("unknown read.multiGet.mode %q (want native or parallel)", read.MultiGet.Mode)
+	}
+	scan := Scan{
+		Mode:       strings.ToLower(v.GetString("scan.mode")),
+		LengthDist: strings.ToLower(v.GetString("scan.lengthDist")),
+		MaxLength:  v.GetInt("scan.maxLength"),
+		Pages:      v.GetInt("scan.pages"),
+	}
+	if err := scan.validate(); err != nil {
+		return nil, err
+	}
+	query := Query{
+		Attribute: v.GetString("query.attribute"),
+		Limit:     v.GetInt("query.limit"),
+		Index:     v.GetBool("query.index"),
+	}
+	if query.Attribute == "" {
+		// атрибут, который есть в каждой строке генератора
+		query.Attribute = "country"
+		if payload.Generator == PayloadRealistic {
+			query.Attribute = "traffic.layer"
+		}
+	}
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+	schema := Schema{Name: v.GetString("schema")}
+	if schema.Name != "" {
+		key := fmt.Sprintf("%s.schemas.%s", db, schema.Name)
+		if !v.IsSet(key) {
+			return nil, fmt.Errorf("schema %q is not defined in %s.schemas", schema.Name, db)
+		}
+		schema.Settings = v.GetStringMapString(key)
+	}
+	options := Settings(v.GetStringMapString(db + ".options"))
+	matrix := Matrix{Levels: v.GetStringSlice("matrix.levels")}
+	report := Report{
+		Dir:     v.GetString("report.dir"),
+		Formats: v.GetStringSlice("report.formats"),
+	}
+	for _, f := range report.Formats {
+		if !reportFormats[f] {
+			return nil, fmt.Errorf("unknown report format %q (want json, csv or md)", f)
+		}
+	}
+	latency := Latency{
+		SignificantFigures: v.GetInt("latency.significantFigures"),
+		MaxTrackable:       v.GetDuration("latency.maxTrackable"),
+	}
+	if latency.SignificantFigures < 1 || latency.SignificantFigures > 5 {
+		return nil, fmt.Errorf("latency.significantFigures must be in [1, 5]")
+	}
+	metrics, err := loadMetrics(v)
+	if err != nil {
+		return nil, err
+	}
 
 	cfg := &Config{
-		DB:             db,
-		URI:            uri,
-		DBName:         dbName,
-		WorkerCount:    workerCount,
-		RecordCount:    recordCount,
-		TableName:      tableName,
-		TestDuration:   testDuration,
-		ConnectTimeout: connectTimeout,
-		ReadsTotal: promauto.NewCounterVec(prometheus.CounterOpts{
-			Name: "ab_reads_total", Help: "Total number of successful reads.",
-		}, []string{"db"}),
-		ReadErrorsTotal: promauto.NewCounterVec(prometheus.CounterOpts{
-			Name: "ab_read_errors_total", Help: "Total number of read errors.",
-		}, []string{"db"}),
-		ReadLatency: promauto.NewHistogramVec(prometheus.HistogramOpts{
-			Name:    "ab_read_latency_seconds",
-			Help:    "Read latency distribution.",
-			Buckets: prometheus.DefBuckets,
-		}, []string{"db"}),
+		DB:              db,
+		URI:             uri,
+		DBName:          dbName,
+		WorkerCount:     workerCount,
+		RecordCount:     recordCount,
+		TableName:       tableName,
+		TestDuration:    testDuration,
+		ConnectTimeout:  connectTimeout,
+		KeyDistribution: keyDistribution,
+		Workload:        workload,
+		Rate:            rate,
+		Phases:          phases,
+		Profile:         profile,
+		FindMax:         findMax,
+		Matrix:          matrix,
+		Seed:            seed,
+		Payload:         payload,
+		Read:            read,
+		Scan:            scan,
+		Query:           query,
+		Schema:          schema,
+		Options:         options,
+		Consistency:     v.GetString("consistency"),
+		Report:          report,
+		Latency:         latency,
+		Metrics:         metrics,
 	}
+	cfg.registerMetrics()
 	return cfg, nil
 }
+
+// Коллекторы Prometheus регистрируются в процессе один раз: повторный
+// LoadConfig (тесты, matrix) получает те же, а бакеты гистограммы берутся
+// из первого конфига.
+var (
+	metricsOnce            sync.Once
+	readsTotal             *prometheus.CounterVec
+	readErrorsTotal        *prometheus.CounterVec
+	readLatency            *prometheus.HistogramVec
+	activeWorkers          *prometheus.GaugeVec
+	readValidationFailures *prometheus.CounterVec
+)
+
+func (c *Config) registerMetrics() {
+	metricsOnce.Do(func() {
+		readsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "ab_reads_total", Help: "Total number of successful operations.",
+		}, []string{"db", "op", "phase", "consistency"})
+		readErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "ab_read_errors_total", Help: "Total number of failed operations by reason.",
+		}, []string{"db", "op", "phase", "reason", "consistency"})
+		readLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ab_read_latency_seconds",
+			Help:    "Operation latency distribution.",
+			Buckets: c.Metrics.Buckets,
+			// 0 отключает native histogram
+			NativeHistogramBucketFactor: c.Metrics.NativeBucketFactor,
+		}, []string{"db", "op", "phase", "consistency"})
+		activeWorkers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ab_active_workers", Help: "Number of running workers.",
+		}, []string{"db"})
+		readValidationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "ab_read_validation_failures_total", Help: "Total number of reads that returned a missing or wrong row.",
+		}, []string{"db", "phase", "consistency"})
+	})
+	c.ReadsTotal = readsTotal
+	c.ReadErrorsTotal = readErrorsTotal
+	c.ReadLatency = readLatency
+	c.ActiveWorkers = activeWorkers
+	c.ReadValidationFailures = readValidationFailures
+}
+
+// loadMetrics берёт явный список metrics.buckets, а если он пуст —
+// строит экспоненциальные бакеты из metrics.exponentialBuckets.
+func loadMetrics(v *viper.Viper) (Metrics, error) {
+	m := Metrics{NativeBucketFactor: v.GetFloat64("metrics.nativeBucketFactor")}
+	for _, b := range v.GetStringSlice("metrics.buckets") {
+		f, err := strconv.ParseFloat(b, 64)
+		if err != nil {
+			return m, fmt.Errorf("invalid metrics.buckets value %q: %w", b, err)
+		}
+		m.Buckets = append(m.Buckets, f)
+	}
+	if len(m.Buckets) == 0 {
+		start := v.GetFloat64("metrics.exponentialBuckets.start")
+		factor := v.GetFloat64("metrics.exponentialBuckets.factor")
+		count := v.GetInt("metrics.exponentialBuckets.count")
+		if start <= 0 || factor <= 1 || count < 1 {
+			return m, fmt.Errorf("metrics.exponentialBuckets needs start > 0, factor > 1, count >= 1")
+		}
+		m.Buckets = prometheus.ExponentialBuckets(start, factor, count)
+	}
+	if !sort.Float64sAreSorted(m.Buckets) {
+		return m, fmt.Errorf("metrics.buckets must be in increasing order")
+	}
+	if m.NativeBucketFactor != 0 && m.NativeBucketFactor <= 1 {
+		return m, fmt.Errorf("metrics.nativeBucketFactor must be > 1 (or 0 to disable)")
+	}
+	return m, nil
+}
+
+// loadProfile читает profile.stages либо разворачивает profile.ramp в стадии.
+// Пустые Workers в стадиях заменяются на workerCount.
+func loadProfile(v *viper.Viper) (Profile, error) {
+	var p Profile
+	if err := v.UnmarshalKey("profile.stages", &p.Stages); err != nil {
+		return p, fmt.Errorf("invalid profile.stages: %w", err)
+	}
+	p.Ramp = Ramp{
+		By:           v.GetString("profile.ramp.by"),
+		From:         v.GetFloat64("profile.ramp.from"),
+		To:           v.GetFloat64("profile.ramp.to"),
+		Steps:        v.GetInt("profile.ramp.steps"),
+		StepDuration: v.GetDuration("profile.ramp.stepDuration"),
+	}
+	if p.Ramp.By != "" {
+		if len(p.Stages) > 0 {
+			return p, fmt.Errorf("profile.stages and profile.ramp are mutually exclusive")
+		}
+		stages, err := p.Ramp.stages(v.GetInt("workerCount"))
+		if err != nil {
+			return p, err
+		}
+		p.Stages = stages
+	}
+	for i := range p.Stages {
+		st := &p.Stages[i]
+		if st.Duration <= 0 {
+			return p, fmt.Errorf("profile.stages[%d].duration must be positive", i)
+		}
+		if st.Workers < 0 || st.TargetOpsPerSec < 0 {
+			return p, fmt.Errorf("profile.stages[%d] must not have negative workers or rate", i)
+		}
+		if st.Workers == 0 {
+			st.Workers = v.GetInt("workerCount")
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,19 @@
+package etcd
+
+import (
+	"context"
+
+	"db-bench/lib/backend"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+var consistencyLevels = []backend.ConfigKey{
+	{Key: "linearizable", Description: "reads go through the raft quorum (default)"},
+	{Key: "serializable", Description: "the contacted member answers from its local state, possibly stale"},
+}
+
+// get reads key at the consistency level of the run.
+func (t *EtcdTester) get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	return t.client.Get(ctx, key, append(opts, t.readOpts...)...)
+}
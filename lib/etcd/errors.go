@@ -0,0 +1,34 @@
+package etcd
+
+import (
+	"errors"
+
+	"db-bench/lib/workload"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (t *EtcdTester) ClassifyError(err error) string {
+	if errors.Is(err, rpctypes.ErrTimeout) || errors.Is(err, rpctypes.ErrTimeoutDueToLeaderFail) ||
+		errors.Is(err, rpctypes.ErrTimeoutDueToConnectionLost) {
+		return workload.ReasonTimeout
+	}
+	code := status.Code(err)
+	var etcdErr rpctypes.EtcdError
+	if errors.As(err, &etcdErr) {
+		code = etcdErr.Code()
+	}
+	switch code {
+	case codes.DeadlineExceeded:
+		return workload.ReasonTimeout
+	case codes.Unavailable:
+		return workload.ReasonUnavailable
+	case codes.ResourceExhausted:
+		return workload.ReasonThrottled
+	case codes.Canceled:
+		return workload.ReasonCanceled
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package etcd
+
+import (
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+var driverOptions = []backend.ConfigKey{
+	{Key: "endpoints", Description: "comma-separated cluster endpoints (default uri)"},
+	{Key: "dialTimeout", Description: "dial timeout (default connect_timeout)"},
+	{Key: "dialKeepAliveTime", Description: "gRPC keepalive ping interval (default off)"},
+	{Key: "dialKeepAliveTimeout", Description: "wait for a keepalive ack this long"},
+	{Key: "autoSyncInterval", Description: "refresh endpoints from the cluster membership (default off)"},
+	{Key: "maxCallRecvMsgSize", Description: "largest response in bytes (default math.MaxInt32)"},
+}
+
+// clientConfig maps etcd.options onto the client config.
+func clientConfig(cfg *conf.Config) (clientv3.Config, error) {
+	o := cfg.Options
+	c := clientv3.Config{Endpoints: o.List("endpoints")}
+	if c.Endpoints == nil {
+		c.Endpoints = []string{cfg.URI}
+	}
+	var err error
+	if c.DialTimeout, err = o.Duration("dialTimeout", cfg.ConnectTimeout); err != nil {
+		return c, err
+	}
+	if c.DialKeepAliveTime, err = o.Duration("dialKeepAliveTime", 0); err != nil {
+		return c, err
+	}
+	if c.DialKeepAliveTimeout, err = o.Duration("dialKeepAliveTimeout", 0); err != nil {
+		return c, err
+	}
+	if c.AutoSyncInterval, err = o.Duration("autoSyncInterval", 0); err != nil {
+		return c, err
+	}
+	if c.MaxCallRecvMsgSize, err = o.Int("maxCallRecvMsgSize", 0); err != nil {
+		return c, err
+	}
+	return c, nil
+}
@@ -0,0 +1,27 @@
+package etcd
+
+import (
+	"context"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+)
+
+func init() {
+	backend.Register(backend.Backend{
+		Name: "etcd",
+		New: func(ctx context.Context, cfg *conf.Config) (backend.Tester, error) {
+			t, err := NewEtcdTester(ctx, cfg)
+			if err != nil {
+				return nil, err
+			}
+			return t, nil
+		},
+		Config: []backend.ConfigKey{
+			{Key: "uri", Description: "etcd endpoint"},
+		},
+		Capabilities: backend.CapBatchSeed,
+		Options:      driverOptions,
+		Consistency:  consistencyLevels,
+	})
+}
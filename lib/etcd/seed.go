@@ -3,43 +3,85 @@ package etcd
 import (
 	"context"
 	"db-bench/lib/conf"
+	"db-bench/lib/seed"
+	"db-bench/lib/workload"
 	"encoding/json"
 	"fmt"
-	"log"
-)
+	"path"
+	"strconv"
 
-func (t *EtcdTester) Seed(ctx context.Context) error {
-	log.Println("Etcd: Writing keys...")
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
 
-	for i := 1; i <= t.cfg.RecordCount; i++ {
-		targetingRulesMap := map[string]interface{}{"country": "US"}
+// maxTxnOps is etcd's default --max-txn-ops; larger batches are split.
+const maxTxnOps = 128
 
-		targetingRulesJSON, err := json.Marshal(targetingRulesMap)
-		if err != nil {
-			return fmt.Errorf("failed to marshal targeting rules: %w", err)
+func (t *EtcdTester) Seed(ctx context.Context) error {
+	return seed.Run(ctx, "Etcd", t.cfg, t, func(ctx context.Context, rows []conf.ExperimentRule) error {
+		for len(rows) > 0 {
+			n := min(len(rows), maxTxnOps)
+			ops := make([]clientv3.Op, 0, n)
+			for _, rule := range rows[:n] {
+				value, err := json.Marshal(rule)
+				if err != nil {
+					return fmt.Errorf("failed to marshal rule %d: %w", rule.ID, err)
+				}
+				ops = append(ops, clientv3.OpPut(t.key(rule.ID), string(value)))
+			}
+			if _, err := t.client.Txn(ctx).Then(ops...).Commit(); err != nil {
+				return err
+			}
+			rows = rows[n:]
 		}
+		return nil
+	})
+}
 
-		rule := conf.ExperimentRule{
-			ID:             int64(i),
-			ExperimentName: fmt.Sprintf("Test %d", i),
-			TargetingRules: string(targetingRulesJSON),
-		}
+func (t *EtcdTester) Count(ctx context.Context) (int64, error) {
+	resp, err := t.client.Get(ctx, t.prefix(), clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
 
-		value, err := json.Marshal(rule)
+// MaxID walks all keys: they sort as strings, not as numbers.
+func (t *EtcdTester) MaxID(ctx context.Context) (int64, error) {
+	const page = 10000
+	var maxID int64
+	from := t.prefix()
+	end := clientv3.GetPrefixRangeEnd(from)
+	for {
+		resp, err := t.client.Get(ctx, from, clientv3.WithRange(end), clientv3.WithKeysOnly(), clientv3.WithLimit(page))
 		if err != nil {
-			return fmt.Errorf("failed to marshal rule %d: %w", i, err)
+			return 0, err
 		}
-
-		key := fmt.Sprintf("/%s/%d", t.cfg.TableName, i)
-		_, err = t.client.Put(ctx, key, string(value))
-		if err != nil {
-			log.Printf("Warning: Etcd put failed for key %s: %v", key, err)
+		for _, kv := range resp.Kvs {
+			if id, err := strconv.ParseInt(path.Base(string(kv.Key)), 10, 64); err == nil {
+				maxID = max(maxID, id)
+			}
 		}
-
-		if i%10000 == 0 {
-			log.Printf("Etcd: %d records prepared...", i)
+		if !resp.More || len(resp.Kvs) == 0 {
+			return maxID, nil
 		}
+		from = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+}
+
+func (t *EtcdTester) Get(ctx context.Context, id int64) (conf.ExperimentRule, error) {
+	var rule conf.ExperimentRule
+	resp, err := t.get(ctx, t.key(id))
+	if err != nil {
+		return rule, err
 	}
+	if len(resp.Kvs) == 0 {
+		return rule, workload.ErrNotFound
+	}
+	err = json.Unmarshal(resp.Kvs[0].Value, &rule)
+	return rule, err
+}
 
-	return nil
+func (t *EtcdTester) Drop(ctx context.Context) error {
+	_, err := t.client.Delete(ctx, t.prefix(), clientv3.WithPrefix())
+	return err
 }
@@ -3,6 +3,7 @@ package etcd
 import (
 	"context"
 	"db-bench/lib/conf"
+	"fmt"
 	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -11,13 +12,16 @@ import (
 type EtcdTester struct {
 	client *clientv3.Client
 	cfg    *conf.Config
+	// options every read adds for its consistency level
+	readOpts []clientv3.OpOption
 }
 
 func NewEtcdTester(ctx context.Context, cfg *conf.Config) (*EtcdTester, error) {
-	client, err := clientv3.New(clientv3.Config{
-		Endpoints:   []string{cfg.URI},
-		DialTimeout: cfg.ConnectTimeout,
-	})
+	cc, err := clientConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("etcd.options: %w", err)
+	}
+	client, err := clientv3.New(cc)
 	if err != nil {
 		return nil, err
 	}
@@ -25,16 +29,20 @@ func NewEtcdTester(ctx context.Context, cfg *conf.Config) (*EtcdTester, error) {
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	_, err = client.Status(ctx, cfg.URI)
+	_, err = client.Status(ctx, cc.Endpoints[0])
 	if err != nil {
 		client.Close()
 		return nil, err
 	}
 
-	return &EtcdTester{
+	t := &EtcdTester{
 		client: client,
 		cfg:    cfg,
-	}, nil
+	}
+	if cfg.Consistency == "serializable" {
+		t.readOpts = []clientv3.OpOption{clientv3.WithSerializable()}
+	}
+	return t, nil
 }
 
 func (t *EtcdTester) Close() {
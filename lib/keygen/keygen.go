@@ -0,0 +1,192 @@
+package keygen
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"db-bench/lib/conf"
+)
+
+// KeyGenerator yields record ids in [1, N]. Generators are not safe for
+// concurrent use; every worker gets its own from Factory.New.
+type KeyGenerator interface {
+	Next() int64
+}
+
+// Factory builds per-worker generators that share run-wide state: the
+// sequential cursor and the highest inserted id used by "latest".
+type Factory struct {
+	dist conf.KeyDistribution
+	seed int64
+	seq  atomic.Int64
+	last atomic.Int64
+	next atomic.Int64
+	zipf *zipfian
+}
+
+// NewFactory prepares generators for the given distribution. The type is
+// expected to be validated by conf.LoadConfig; unknown types fall back to uniform.
+func NewFactory(dist conf.KeyDistribution, recordCount int64) *Factory {
+	f := &Factory{dist: dist, seed: dist.Seed}
+	if f.seed == 0 {
+		f.seed = time.Now().UnixNano()
+	}
+	f.last.Store(recordCount)
+	f.next.Store(recordCount)
+
+	switch dist.Type {
+	case conf.DistZipfian, conf.DistLatest:
+		// zeta(n) is O(n), so compute it once and let workers copy it.
+		f.zipf = newZipfian(recordCount, dist.ZipfianTheta)
+	}
+	return f
+}
+
+// Advance records that id now exists, extending the key space for "latest"
+// and for the other distributions' upper bound.
+func (f *Factory) Advance(id int64) {
+	for {
+		cur := f.last.Load()
+		if id <= cur || f.last.CompareAndSwap(cur, id) {
+			return
+		}
+	}
+}
+
+// InsertAfter makes NextInsertID continue past id, e.g. past the rows an
+// earlier run inserted beyond the seeded range.
+func (f *Factory) InsertAfter(id int64) {
+	for {
+		cur := f.next.Load()
+		if id <= cur || f.next.CompareAndSwap(cur, id) {
+			return
+		}
+	}
+}
+
+// NextInsertID reserves a fresh id past the seeded range. Call Advance once
+// the row is written so readers start picking it.
+func (f *Factory) NextInsertID() int64 { return f.next.Add(1) }
+
+// Latest returns the highest id known to exist.
+func (f *Factory) Latest() int64 { return f.last.Load() }
+
+// New returns a generator for the worker with the given index.
+func (f *Factory) New(worker int) KeyGenerator {
+	rnd := rand.New(rand.NewSource(f.seed + int64(worker)))
+
+	switch f.dist.Type {
+	case conf.DistZipfian:
+		z := *f.zipf
+		z.rnd = rnd
+		return &zipfianGen{f: f, z: &z, scrambled: f.dist.Scrambled}
+	case conf.DistLatest:
+		z := *f.zipf
+		z.rnd = rnd
+		return &latestGen{f: f, z: &z}
+	case conf.DistHotspot:
+		return &hotspotGen{f: f, rnd: rnd}
+	case conf.DistSequential:
+		return &sequentialGen{f: f}
+	case conf.DistExponential:
+		return newExponentialGen(f, rnd)
+	default:
+		return &uniformGen{f: f, rnd: rnd}
+	}
+}
+
+type uniformGen struct {
+	f   *Factory
+	rnd *rand.Rand
+}
+
+func (g *uniformGen) Next() int64 {
+	return g.rnd.Int63n(g.f.Latest()) + 1
+}
+
+type sequentialGen struct {
+	f *Factory
+}
+
+func (g *sequentialGen) Next() int64 {
+	return (g.f.seq.Add(1)-1)%g.f.Latest() + 1
+}
+
+// hotspotGen sends HotspotOpFraction of operations to the first
+// HotspotDataFraction of the key space.
+type hotspotGen struct {
+	f   *Factory
+	rnd *rand.Rand
+}
+
+func (g *hotspotGen) Next() int64 {
+	n := g.f.Latest()
+	hot := int64(float64(n) * g.f.dist.HotspotDataFraction)
+	if hot < 1 {
+		hot = 1
+	}
+	if g.rnd.Float64() < g.f.dist.HotspotOpFraction || hot >= n {
+		return g.rnd.Int63n(hot) + 1
+	}
+	return hot + g.rnd.Int63n(n-hot) + 1
+}
+
+type zipfianGen struct {
+	f         *Factory
+	z         *zipfian
+	scrambled bool
+}
+
+func (g *zipfianGen) Next() int64 {
+	n := g.f.Latest()
+	rank := g.z.next(n)
+	if g.scrambled {
+		// Spread the hot ranks over the key space so they don't share a page or partition.
+		h := fnv.New64a()
+		var b [8]byte
+		for i := range b {
+			b[i] = byte(rank >> (8 * i))
+		}
+		h.Write(b[:])
+		return int64(h.Sum64()%uint64(n)) + 1
+	}
+	return rank + 1
+}
+
+// latestGen favours the most recently inserted ids.
+type latestGen struct {
+	f *Factory
+	z *zipfian
+}
+
+func (g *latestGen) Next() int64 {
+	n := g.f.Latest()
+	return n - g.z.next(n)
+}
+
+// exponentialGen follows YCSB: ExponentialPercentile percent of operations hit
+// the newest ExponentialFraction of the key space.
+type exponentialGen struct {
+	f     *Factory
+	rnd   *rand.Rand
+	gamma float64
+}
+
+func newExponentialGen(f *Factory, rnd *rand.Rand) *exponentialGen {
+	n := float64(f.Latest())
+	gamma := -math.Log(1-f.dist.ExponentialPercentile/100) / (n * f.dist.ExponentialFraction)
+	return &exponentialGen{f: f, rnd: rnd, gamma: gamma}
+}
+
+func (g *exponentialGen) Next() int64 {
+	n := g.f.Latest()
+	for {
+		off := int64(-math.Log(1-g.rnd.Float64()) / g.gamma)
+		if off < n {
+			return n - off
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package keygen
+
+import (
+	"math"
+	"testing"
+
+	"db-bench/lib/conf"
+)
+
+const (
+	testRecords = 10000
+	testDraws   = 200000
+)
+
+func testDist(typ string) conf.KeyDistribution {
+	return conf.KeyDistribution{
+		Type:                  typ,
+		ZipfianTheta:          0.99,
+		HotspotDataFraction:   0.2,
+		HotspotOpFraction:     0.8,
+		ExponentialPercentile: 95,
+		ExponentialFraction:   0.8571428571,
+		Seed:                  1,
+	}
+}
+
+// draw returns how often each id in [1, testRecords] came up.
+func draw(t *testing.T, dist conf.KeyDistribution) []int {
+	t.Helper()
+	g := NewFactory(dist, testRecords).New(0)
+	counts := make([]int, testRecords+1)
+	for range testDraws {
+		id := g.Next()
+		if id < 1 || id > testRecords {
+			t.Fatalf("%s: id %d outside [1, %d]", dist.Type, id, testRecords)
+		}
+		counts[id]++
+	}
+	return counts
+}
+
+// share is the fraction of draws that fell on ids in [from, to].
+func share(counts []int, from, to int) float64 {
+	n := 0
+	for _, c := range counts[from : to+1] {
+		n += c
+	}
+	return float64(n) / testDraws
+}
+
+func TestRange(t *testing.T) {
+	for _, typ := range []string{conf.DistUniform, conf.DistZipfian, conf.DistHotspot, conf.DistLatest,
+		conf.DistSequential, conf.DistExponential} {
+		t.Run(typ, func(t *testing.T) {
+			draw(t, testDist(typ))
+			dist := testDist(typ)
+			dist.Scrambled = true
+			draw(t, dist)
+		})
+	}
+}
+
+func TestSkew(t *testing.T) {
+	// share of the 10% most popular ranks under zipfian
+	top := zeta(0, testRecords/10, 0.99, 0) / zeta(0, testRecords, 0.99, 0)
+	// exponential: 95% of the unbounded distribution falls on the newest
+	// fraction; offsets past the key space are redrawn, which raises it.
+	newest := 0.95 / (1 - math.Pow(0.05, 1/0.8571428571))
+	tests := []struct {
+		typ      string
+		from, to int
+		want     float64
+	}{
+		{conf.DistUniform, 1, testRecords / 2, 0.5},
+		{conf.DistHotspot, 1, testRecords / 5, 0.8},
+		{conf.DistZipfian, 1, testRecords / 10, top},
+		{conf.DistLatest, testRecords - testRecords/10 + 1, testRecords, top},
+		{conf.DistExponential, testRecords - testRecords*6/7 + 1, testRecords, newest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			got := share(draw(t, testDist(tt.typ)), tt.from, tt.to)
+			if math.Abs(got-tt.want) > 0.02 {
+				t.Errorf("ids %d..%d got %.3f of draws, want %.3f", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZipfianHottest(t *testing.T) {
+	counts := draw(t, testDist(conf.DistZipfian))
+	want := 1 / zeta(0, testRecords, 0.99, 0)
+	if got := float64(counts[1]) / testDraws; math.Abs(got-want) > 0.01 {
+		t.Errorf("id 1 got %.3f of draws, want %.3f", got, want)
+	}
+	for id := 2; id <= 10; id++ {
+		if counts[id] > counts[id-1] {
+			t.Errorf("id %d drawn %d times, more than id %d (%d)", id, counts[id], id-1, counts[id-1])
+		}
+	}
+}
+
+func TestSequential(t *testing.T) {
+	g := NewFactory(testDist(conf.DistSequential), 3).New(0)
+	for i, want := range []int64{1, 2, 3, 1, 2} {
+		if got := g.Next(); got != want {
+			t.Fatalf("draw %d: got %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestInsertAfter(t *testing.T) {
+	f := NewFactory(testDist(conf.DistUniform), 100)
+	if got := f.NextInsertID(); got != 101 {
+		t.Fatalf("first insert id %d, want 101", got)
+	}
+	f.InsertAfter(500)
+	f.InsertAfter(200)
+	if got := f.NextInsertID(); got != 501 {
+		t.Fatalf("insert id after InsertAfter(500) %d, want 501", got)
+	}
+}
@@ -0,0 +1,68 @@
+package keygen
+
+import (
+	"math"
+	"math/rand"
+)
+
+// zipfian draws ranks in [0, n) following Gray et al., "Quickly Generating
+// Billion-Record Synthetic Databases", as used by YCSB. Rank 0 is the most
+// popular. Unlike rand.Zipf it supports 0 < theta < 1.
+type zipfian struct {
+	rnd   *rand.Rand
+	items int64
+	theta float64
+	alpha float64
+	zeta2 float64
+	zetan float64
+	eta   float64
+}
+
+func newZipfian(items int64, theta float64) *zipfian {
+	z := &zipfian{
+		theta: theta,
+		alpha: 1 / (1 - theta),
+		zeta2: zeta(0, 2, theta, 0),
+	}
+	z.resize(items)
+	return z
+}
+
+// resize updates zeta(n) incrementally when the key space grows.
+func (z *zipfian) resize(items int64) {
+	if items == z.items {
+		return
+	}
+	if items > z.items {
+		z.zetan = zeta(z.items, items, z.theta, z.zetan)
+	} else {
+		z.zetan = zeta(0, items, z.theta, 0)
+	}
+	z.items = items
+	z.eta = (1 - math.Pow(2/float64(items), 1-z.theta)) / (1 - z.zeta2/z.zetan)
+}
+
+func (z *zipfian) next(items int64) int64 {
+	z.resize(items)
+	u := z.rnd.Float64()
+	uz := u * z.zetan
+	if uz < 1 {
+		return 0
+	}
+	if uz < 1+math.Pow(0.5, z.theta) {
+		return 1
+	}
+	rank := int64(float64(z.items) * math.Pow(z.eta*u-z.eta+1, z.alpha))
+	if rank >= z.items {
+		rank = z.items - 1
+	}
+	return rank
+}
+
+func zeta(from, to int64, theta, initial float64) float64 {
+	sum := initial
+	for i := from; i < to; i++ {
+		sum += 1 / math.Pow(float64(i+1), theta)
+	}
+	return sum
+}
@@ -0,0 +1,34 @@
+package mongo
+
+import (
+	"db-bench/lib/backend"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+var consistencyLevels = []backend.ConfigKey{
+	{Key: "local", Description: "read concern local on the read preference of mongo.options (default)"},
+	{Key: "available", Description: "read concern available"},
+	{Key: "majority", Description: "read concern majority: data acknowledged by a majority"},
+	{Key: "linearizable", Description: "read concern linearizable, from the primary"},
+	{Key: "secondaryPreferred", Description: "read concern local from a secondary when one is available"},
+}
+
+// collectionOptions returns the options of the collection reads go through
+// at the given level.
+func collectionOptions(level string) *options.CollectionOptions {
+	co := options.Collection()
+	switch level {
+	case "available":
+		co.SetReadConcern(readconcern.Available())
+	case "majority":
+		co.SetReadConcern(readconcern.Majority())
+	case "linearizable":
+		co.SetReadConcern(readconcern.Linearizable()).SetReadPreference(readpref.Primary())
+	case "secondaryPreferred":
+		co.SetReadConcern(readconcern.Local()).SetReadPreference(readpref.SecondaryPreferred())
+	}
+	return co
+}
@@ -0,0 +1,21 @@
+package mongo
+
+import (
+	"errors"
+
+	"db-bench/lib/workload"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func (t *MongoTester) ClassifyError(err error) string {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return workload.ReasonNotFound
+	case mongo.IsTimeout(err):
+		return workload.ReasonTimeout
+	case mongo.IsNetworkError(err), errors.Is(err, mongo.ErrClientDisconnected):
+		return workload.ReasonUnavailable
+	}
+	return ""
+}
@@ -0,0 +1,189 @@
+package mongo
+
+import (
+	"context"
+	"db-bench/lib/conf"
+	"db-bench/lib/workload"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	idOnly     = options.FindOne().SetProjection(bson.M{"_id": 0, "id": 1})
+	manyIDOnly = options.Find().SetProjection(bson.M{"_id": 0, "id": 1})
+)
+
+func (t *MongoTester) Read(ctx context.Context, id int64) error {
+	opts := options.FindOne()
+	if t.cfg.Read.Projection == conf.ProjectionID {
+		opts = idOnly
+	}
+	var doc document
+	err := t.collection.FindOne(ctx, bson.M{"id": id}, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return workload.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return doc.decode(t.cfg.Read.Projection)
+}
+
+func (t *MongoTester) ReadMany(ctx context.Context, ids []int64) error {
+	opts := options.Find()
+	if t.cfg.Read.Projection == conf.ProjectionID {
+		opts = manyIDOnly
+	}
+	cur, err := t.collection.Find(ctx, bson.M{"id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	found := 0
+	for cur.Next(ctx) {
+		found++
+		if t.cfg.Read.Projection == conf.ProjectionID {
+			continue
+		}
+		var doc document
+		if err := cur.Decode(&doc); err != nil {
+			return err
+		}
+		if err := doc.decode(t.cfg.Read.Projection); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+	return workload.Found(found, len(ids))
+}
+
+func (t *MongoTester) Scan(ctx context.Context, q workload.ScanQuery) (int, int64, error) {
+	filter := bson.M{}
+	opts := options.Find().SetSort(bson.D{{Key: "id", Value: 1}})
+	switch q.Mode {
+	case conf.ScanRange:
+		filter = bson.M{"id": bson.M{"$gte": q.From, "$lt": q.To()}}
+	case conf.ScanKeyset:
+		filter = bson.M{"id": bson.M{"$gt": q.From}}
+		opts.SetLimit(int64(q.Limit))
+	case conf.ScanOffset:
+		opts.SetSkip(int64(q.Offset)).SetLimit(int64(q.Limit))
+	}
+	return t.find(ctx, filter, opts)
+}
+
+func (t *MongoTester) Query(ctx context.Context, value any) (int, error) {
+	// Served by the index Seed creates with query.index; numbers match
+	// whatever numeric type they were stored as.
+	filter := bson.M{"targeting_rules." + t.cfg.Query.Attribute: value}
+	n, _, err := t.find(ctx, filter, options.Find().SetLimit(int64(t.cfg.Query.Limit)))
+	return n, err
+}
+
+// find reads the documents of a scan or query, fetching as much of each as
+// the projection asks for, and returns their number and the last id.
+func (t *MongoTester) find(ctx context.Context, filter bson.M, opts *options.FindOptions) (int, int64, error) {
+	if t.cfg.Read.Projection == conf.ProjectionID {
+		opts.SetProjection(bson.M{"_id": 0, "id": 1})
+	}
+	cur, err := t.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cur.Close(ctx)
+
+	n := 0
+	var doc document
+	for cur.Next(ctx) {
+		if err := cur.Decode(&doc); err != nil {
+			return n, doc.ID, err
+		}
+		if err := doc.decode(t.cfg.Read.Projection); err != nil {
+			return n, doc.ID, err
+		}
+		n++
+	}
+	return n, doc.ID, cur.Err()
+}
+
+func (t *MongoTester) Update(ctx context.Context, rule conf.ExperimentRule) error {
+	doc, err := ruleDocument(rule)
+	if err != nil {
+		return err
+	}
+	delete(doc, "id")
+	res, err := t.collection.UpdateOne(ctx, bson.M{"id": rule.ID}, bson.M{"$set": doc})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return workload.ErrNotFound
+	}
+	return nil
+}
+
+func (t *MongoTester) Insert(ctx context.Context, rule conf.ExperimentRule) error {
+	doc, err := ruleDocument(rule)
+	if err != nil {
+		return err
+	}
+	_, err = t.collection.InsertOne(ctx, doc)
+	return err
+}
+
+func (t *MongoTester) Delete(ctx context.Context, id int64) error {
+	_, err := t.collection.DeleteOne(ctx, bson.M{"id": id})
+	return err
+}
+
+// document is a row as stored in the collection. The targeting rules are a
+// subdocument, so that queries can reach into them.
+type document struct {
+	ID             int64    `bson:"id"`
+	ExperimentName string   `bson:"experiment_name"`
+	TargetingRules bson.Raw `bson:"targeting_rules"`
+}
+
+// rule converts the document back to a row, with the targeting rules as
+// relaxed extended JSON: plain JSON for what the generator writes.
+func (d document) rule() (conf.ExperimentRule, error) {
+	rules, err := bson.MarshalExtJSON(d.TargetingRules, false, false)
+	if err != nil {
+		return conf.ExperimentRule{ID: d.ID}, fmt.Errorf("failed to convert targeting_rules of %d: %w", d.ID, err)
+	}
+	return conf.ExperimentRule{ID: d.ID, ExperimentName: d.ExperimentName, TargetingRules: string(rules)}, nil
+}
+
+// decode parses the targeting rules if projection asks for it, as
+// workload.DecodeRow does for the databases that store JSON text.
+func (d document) decode(projection string) error {
+	if projection != conf.ProjectionDecode {
+		return nil
+	}
+	var rules bson.M
+	if err := bson.Unmarshal(d.TargetingRules, &rules); err != nil {
+		return fmt.Errorf("failed to decode targeting_rules of %d: %w", d.ID, err)
+	}
+	return nil
+}
+
+// ruleDocument converts a row to a document; bson.D keeps the order of the
+// JSON keys.
+func ruleDocument(rule conf.ExperimentRule) (bson.M, error) {
+	var rules bson.D
+	if err := bson.UnmarshalExtJSON([]byte(rule.TargetingRules), false, &rules); err != nil {
+		return nil, fmt.Errorf("failed to convert targeting_rules of %d: %w", rule.ID, err)
+	}
+	return bson.M{
+		"id":              rule.ID,
+		"experiment_name": rule.ExperimentName,
+		"targeting_rules": rules,
+	}, nil
+}
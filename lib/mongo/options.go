@@ -0,0 +1,74 @@
+package mongo
+
+import (
+	"fmt"
+	"time"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+var driverOptions = []backend.ConfigKey{
+	{Key: "maxPoolSize", Description: "connections per server (default 100)"},
+	{Key: "minPoolSize", Description: "connections kept open while idle (default 0)"},
+	{Key: "maxConnIdleTime", Description: "close connections idle for this long (default unlimited)"},
+	{Key: "timeout", Description: "client-side timeout of every operation (default none)"},
+	{Key: "serverSelectionTimeout", Description: "wait for a suitable server this long (default 30s)"},
+	{Key: "heartbeatInterval", Description: "server monitoring interval (default 10s)"},
+	{Key: "compressors", Description: "wire compressors in order of preference: snappy, zlib, zstd"},
+	{Key: "readPreference", Description: "primary (default), primaryPreferred, secondary, secondaryPreferred or nearest"},
+}
+
+// applyOptions maps mongo.options onto the client options; settings that are
+// not set keep the URI's or the driver's value.
+func applyOptions(co *options.ClientOptions, o conf.Settings) error {
+	for key, set := range map[string]func(uint64){
+		"maxPoolSize": func(n uint64) { co.SetMaxPoolSize(n) },
+		"minPoolSize": func(n uint64) { co.SetMinPoolSize(n) },
+	} {
+		if _, ok := o[key]; !ok {
+			continue
+		}
+		n, err := o.Int(key, 0)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return fmt.Errorf("%s must not be negative", key)
+		}
+		set(uint64(n))
+	}
+	for key, set := range map[string]func(d time.Duration){
+		"maxConnIdleTime":        func(d time.Duration) { co.SetMaxConnIdleTime(d) },
+		"timeout":                func(d time.Duration) { co.SetTimeout(d) },
+		"serverSelectionTimeout": func(d time.Duration) { co.SetServerSelectionTimeout(d) },
+		"heartbeatInterval":      func(d time.Duration) { co.SetHeartbeatInterval(d) },
+	} {
+		if _, ok := o[key]; !ok {
+			continue
+		}
+		d, err := o.Duration(key, 0)
+		if err != nil {
+			return err
+		}
+		set(d)
+	}
+	if compressors := o.List("compressors"); compressors != nil {
+		co.SetCompressors(compressors)
+	}
+	if mode, ok := o["readPreference"]; ok {
+		m, err := readpref.ModeFromString(mode)
+		if err != nil {
+			return err
+		}
+		rp, err := readpref.New(m)
+		if err != nil {
+			return err
+		}
+		co.SetReadPreference(rp)
+	}
+	return co.Validate()
+}
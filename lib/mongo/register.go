@@ -0,0 +1,26 @@
+package mongo
+
+import (
+	"context"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+)
+
+func init() {
+	backend.Register(backend.Backend{
+		Name: "mongo",
+		New: func(ctx context.Context, cfg *conf.Config) (backend.Tester, error) {
+			t, err := NewMongoTester(ctx, cfg)
+			if err != nil {
+				return nil, err
+			}
+			return t, nil
+		},
+		Config:       backend.CommonConfig,
+		Capabilities: backend.CapBatchSeed | backend.CapNativeJSON | backend.CapJSONQuery,
+		Schema:       schemaSettings,
+		Options:      driverOptions,
+		Consistency:  consistencyLevels,
+	})
+}
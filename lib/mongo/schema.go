@@ -0,0 +1,47 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var schemaSettings = []backend.ConfigKey{
+	{Key: "compression", Description: "WiredTiger block compressor: snappy (server default), zlib, zstd or none"},
+}
+
+var blockCompressors = map[string]bool{"snappy": true, "zlib": true, "zstd": true, "none": true}
+
+// blockCompressor returns the compressor the schema variant selects, or ""
+// for the server default.
+func blockCompressor(s conf.Schema) (string, error) {
+	c := s.Setting("compression", "")
+	if c != "" && !blockCompressors[c] {
+		return "", fmt.Errorf("unknown compression %q (want snappy, zlib, zstd or none)", c)
+	}
+	return c, nil
+}
+
+// createCollection creates the collection with the storage options of the
+// schema variant. Without any, MongoDB creates it on first insert.
+func (t *MongoTester) createCollection(ctx context.Context) error {
+	if t.compressor == "" {
+		return nil
+	}
+	opts := options.CreateCollection().SetStorageEngine(bson.M{
+		"wiredTiger": bson.M{"configString": "block_compressor=" + t.compressor},
+	})
+	err := t.collection.Database().CreateCollection(ctx, t.collection.Name(), opts)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == 48 { // NamespaceExists: seeded before
+		return nil
+	}
+	return err
+}
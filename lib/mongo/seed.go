@@ -2,7 +2,10 @@ package mongo
 
 import (
 	"context"
-	"encoding/json"
+	"db-bench/lib/conf"
+	"db-bench/lib/seed"
+	"db-bench/lib/workload"
+	"errors"
 	"fmt"
 	"log"
 
@@ -12,6 +15,9 @@ import (
 )
 
 func (t *MongoTester) Seed(ctx context.Context) error {
+	if err := t.createCollection(ctx); err != nil {
+		return err
+	}
 	// Create index on id field for better read performance
 	indexModel := mongo.IndexModel{
 		Keys:    bson.D{{Key: "id", Value: 1}},
@@ -22,43 +28,78 @@ func (t *MongoTester) Seed(ctx context.Context) error {
 		log.Printf("Warning: Failed to create index: %v", err)
 	}
 
-	log.Println("MongoDB: Writing documents...")
-
-	// Prepare documents in batches for better performance
-	batchSize := 1000
-	documents := make([]interface{}, 0, batchSize)
-
-	for i := 1; i <= t.cfg.RecordCount; i++ {
-		// Create targeting rules as a map first
-		targetingRulesMap := map[string]interface{}{"country": "US"}
-
-		// Convert to JSON string if TargetingRules is defined as string
-		targetingRulesJSON, err := json.Marshal(targetingRulesMap)
-		if err != nil {
-			return fmt.Errorf("failed to marshal targeting rules: %w", err)
-		}
-
-		doc := bson.M{
-			"id":              int64(i),
-			"experiment_name": fmt.Sprintf("Test %d", i),
-			"targeting_rules": string(targetingRulesJSON),
-		}
-		documents = append(documents, doc)
-
-		// Insert in batches
-		if len(documents) == batchSize || i == t.cfg.RecordCount {
-			opts := options.InsertMany().SetOrdered(false)
-			_, err := t.collection.InsertMany(ctx, documents, opts)
+	opts := options.InsertMany().SetOrdered(false)
+	err = seed.Run(ctx, "MongoDB", t.cfg, t, func(ctx context.Context, rows []conf.ExperimentRule) error {
+		documents := make([]interface{}, len(rows))
+		for i, rule := range rows {
+			doc, err := ruleDocument(rule)
 			if err != nil {
-				log.Printf("Warning: MongoDB batch insert failed: %v", err)
+				return err
 			}
-			documents = documents[:0] // Clear slice but keep capacity
+			documents[i] = doc
+		}
+		_, err := t.collection.InsertMany(ctx, documents, opts)
+		if onlyDuplicates(err) {
+			// rows left over from a previous seed
+			return nil
 		}
+		return err
+	})
+	if err != nil || !t.cfg.Query.Index {
+		return err
+	}
+	field := "targeting_rules." + t.cfg.Query.Attribute
+	log.Printf("MongoDB: Creating index on %s...", field)
+	if _, err := t.collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: field, Value: 1}}}); err != nil {
+		return fmt.Errorf("failed to create index on %s: %w", field, err)
+	}
+	return nil
+}
 
-		if i%10000 == 0 {
-			log.Printf("MongoDB: %d records prepared...", i)
+// onlyDuplicates reports whether every failed write of an unordered
+// InsertMany hit an existing id.
+func onlyDuplicates(err error) bool {
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || bwe.WriteConcernError != nil || len(bwe.WriteErrors) == 0 {
+		return false
+	}
+	for _, we := range bwe.WriteErrors {
+		if we.Code != 11000 {
+			return false
 		}
 	}
+	return true
+}
 
-	return nil
+func (t *MongoTester) Count(ctx context.Context) (int64, error) {
+	return t.collection.CountDocuments(ctx, bson.M{})
+}
+
+func (t *MongoTester) MaxID(ctx context.Context) (int64, error) {
+	var doc struct {
+		ID int64 `bson:"id"`
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}}).SetProjection(bson.M{"id": 1})
+	err := t.collection.FindOne(ctx, bson.M{}, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return doc.ID, err
+}
+
+func (t *MongoTester) Get(ctx context.Context, id int64) (conf.ExperimentRule, error) {
+	var doc document
+	err := t.collection.FindOne(ctx, bson.M{"id": id}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return conf.ExperimentRule{ID: id}, workload.ErrNotFound
+	}
+	if err != nil {
+		return conf.ExperimentRule{ID: id}, err
+	}
+	return doc.rule()
+}
+
+// Drop removes the collection together with its id index.
+func (t *MongoTester) Drop(ctx context.Context) error {
+	return t.collection.Drop(ctx)
 }
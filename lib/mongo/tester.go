@@ -3,6 +3,7 @@ package mongo
 import (
 	"context"
 	"db-bench/lib/conf"
+	"fmt"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -12,10 +13,19 @@ type MongoTester struct {
 	client     *mongo.Client
 	collection *mongo.Collection
 	cfg        *conf.Config
+	// WiredTiger block compressor of the schema variant, "" for the default
+	compressor string
 }
 
 func NewMongoTester(ctx context.Context, cfg *conf.Config) (*MongoTester, error) {
+	compressor, err := blockCompressor(cfg.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("mongo.schemas.%s: %w", cfg.Schema.Name, err)
+	}
 	clientOptions := options.Client().ApplyURI(cfg.URI)
+	if err := applyOptions(clientOptions, cfg.Options); err != nil {
+		return nil, fmt.Errorf("mongo.options: %w", err)
+	}
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
 		return nil, err
@@ -29,12 +39,13 @@ func NewMongoTester(ctx context.Context, cfg *conf.Config) (*MongoTester, error)
 	}
 
 	db := client.Database(cfg.DBName)
-	collection := db.Collection(cfg.TableName)
+	collection := db.Collection(cfg.TableName, collectionOptions(cfg.Consistency))
 
 	return &MongoTester{
 		client:     client,
 		collection: collection,
 		cfg:        cfg,
+		compressor: compressor,
 	}, nil
 }
 
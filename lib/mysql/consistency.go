@@ -0,0 +1,19 @@
+package mysql
+
+import "db-bench/lib/backend"
+
+var consistencyLevels = []backend.ConfigKey{
+	{Key: "repeatable_read", Description: "isolation level REPEATABLE READ (default)"},
+	{Key: "read_committed", Description: "isolation level READ COMMITTED"},
+	{Key: "read_uncommitted", Description: "isolation level READ UNCOMMITTED"},
+	{Key: "serializable", Description: "isolation level SERIALIZABLE"},
+}
+
+// isolationLevels maps the consistency key onto the session's
+// transaction_isolation, which the driver sets on every new connection.
+var isolationLevels = map[string]string{
+	"repeatable_read":  "'REPEATABLE-READ'",
+	"read_committed":   "'READ-COMMITTED'",
+	"read_uncommitted": "'READ-UNCOMMITTED'",
+	"serializable":     "'SERIALIZABLE'",
+}
@@ -0,0 +1,31 @@
+package mysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"db-bench/lib/workload"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func (t *MySQLTester) ClassifyError(err error) string {
+	var myErr *mysql.MySQLError
+	switch {
+	case errors.As(err, &myErr):
+		switch myErr.Number {
+		case 1205, 3024: // lock wait timeout, max_execution_time exceeded
+			return workload.ReasonTimeout
+		case 1040, 1203: // too many connections (per server, per user)
+			return workload.ReasonThrottled
+		case 1053, 2006, 2013: // server shutdown, server gone away, lost connection
+			return workload.ReasonUnavailable
+		case 3140: // invalid JSON text
+			return workload.ReasonDecode
+		}
+	case errors.Is(err, mysql.ErrInvalidConn), errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return workload.ReasonUnavailable
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var driverOptions = []backend.ConfigKey{
+	{Key: "maxOpenConns", Description: "pool size (default workers + 10)"},
+	{Key: "maxIdleConns", Description: "idle connections kept in the pool (default workers / 2)"},
+	{Key: "connMaxLifetime", Description: "close connections older than this (default unlimited)"},
+	{Key: "connMaxIdleTime", Description: "close connections idle for this long (default unlimited)"},
+	{Key: "timeout", Description: "dial timeout; overrides the DSN parameter"},
+	{Key: "readTimeout", Description: "I/O read timeout; overrides the DSN parameter"},
+	{Key: "writeTimeout", Description: "I/O write timeout; overrides the DSN parameter"},
+	{Key: "compress", Description: "true: compressed client protocol"},
+}
+
+// open parses the DSN, applies mysql.options and the isolation level of the
+// consistency key on top of it and opens the pool.
+func open(cfg *conf.Config) (*sql.DB, error) {
+	mc, err := mysql.ParseDSN(cfg.URI)
+	if err != nil {
+		return nil, err
+	}
+	o := cfg.Options
+	if mc.Timeout, err = o.Duration("timeout", mc.Timeout); err != nil {
+		return nil, fmt.Errorf("mysql.options: %w", err)
+	}
+	if mc.ReadTimeout, err = o.Duration("readTimeout", mc.ReadTimeout); err != nil {
+		return nil, fmt.Errorf("mysql.options: %w", err)
+	}
+	if mc.WriteTimeout, err = o.Duration("writeTimeout", mc.WriteTimeout); err != nil {
+		return nil, fmt.Errorf("mysql.options: %w", err)
+	}
+	if level, ok := isolationLevels[cfg.Consistency]; ok {
+		if mc.Params == nil {
+			mc.Params = map[string]string{}
+		}
+		mc.Params["transaction_isolation"] = level
+	}
+	compress, err := o.Bool("compress", false)
+	if err != nil {
+		return nil, fmt.Errorf("mysql.options: %w", err)
+	}
+	if compress {
+		if err := mc.Apply(mysql.EnableCompression(true)); err != nil {
+			return nil, err
+		}
+	}
+	maxOpen, err := o.Int("maxOpenConns", cfg.WorkerCount+10)
+	if err != nil {
+		return nil, fmt.Errorf("mysql.options: %w", err)
+	}
+	maxIdle, err := o.Int("maxIdleConns", cfg.WorkerCount/2)
+	if err != nil {
+		return nil, fmt.Errorf("mysql.options: %w", err)
+	}
+	lifetime, err := o.Duration("connMaxLifetime", 0)
+	if err != nil {
+		return nil, fmt.Errorf("mysql.options: %w", err)
+	}
+	idleTime, err := o.Duration("connMaxIdleTime", 0)
+	if err != nil {
+		return nil, fmt.Errorf("mysql.options: %w", err)
+	}
+
+	connector, err := mysql.NewConnector(mc)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(connector)
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(lifetime)
+	db.SetConnMaxIdleTime(idleTime)
+	return db, nil
+}
@@ -0,0 +1,26 @@
+package mysql
+
+import (
+	"context"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+)
+
+func init() {
+	backend.Register(backend.Backend{
+		Name: "mysql",
+		New: func(ctx context.Context, cfg *conf.Config) (backend.Tester, error) {
+			t, err := NewMySQLTester(ctx, cfg)
+			if err != nil {
+				return nil, err
+			}
+			return t, nil
+		},
+		Config:       backend.CommonConfig,
+		Capabilities: backend.CapBatchSeed | backend.CapNativeJSON | backend.CapJSONQuery,
+		Schema:       schemaSettings,
+		Options:      driverOptions,
+		Consistency:  consistencyLevels,
+	})
+}
@@ -0,0 +1,133 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+	"db-bench/lib/workload"
+)
+
+var schemaSettings = []backend.ConfigKey{
+	{Key: "keyType", Description: "bigint (default) or uuid: primary key on a BINARY(16) UUID derived from the id"},
+	{Key: "rowFormat", Description: "InnoDB ROW_FORMAT: DYNAMIC (default), COMPACT, REDUNDANT or COMPRESSED"},
+	{Key: "keyBlockSize", Description: "KEY_BLOCK_SIZE in KB for ROW_FORMAT=COMPRESSED: 1, 2, 4, 8 or 16"},
+	{Key: "compression", Description: "InnoDB page compression: zlib, lz4 or none (default)"},
+}
+
+// schema is the table layout a schema variant selects.
+type schema struct {
+	// uuid keys the table on uuid_key, a UUID derived from id (workload.UUID);
+	// id stays a plain column.
+	uuid         bool
+	rowFormat    string
+	keyBlockSize int
+	compression  string
+}
+
+func newSchema(cfg *conf.Config) (schema, error) {
+	var s schema
+	var err error
+	switch keyType := cfg.Schema.Setting("keyType", "bigint"); keyType {
+	case "bigint":
+	case "uuid":
+		s.uuid = true
+		if cfg.Workload.Scan > 0 && cfg.Scan.Mode == conf.ScanRange {
+			return s, fmt.Errorf("scan.mode range needs bigint keys")
+		}
+	default:
+		return s, fmt.Errorf("unknown keyType %q (want bigint or uuid)", keyType)
+	}
+	switch s.rowFormat = strings.ToUpper(cfg.Schema.Setting("rowFormat", "")); s.rowFormat {
+	case "", "DYNAMIC", "COMPACT", "REDUNDANT", "COMPRESSED":
+	default:
+		return s, fmt.Errorf("unknown rowFormat %q (want DYNAMIC, COMPACT, REDUNDANT or COMPRESSED)", s.rowFormat)
+	}
+	if s.keyBlockSize, err = cfg.Schema.Int("keyBlockSize", 0); err != nil {
+		return s, err
+	}
+	switch s.keyBlockSize {
+	case 0:
+	case 1, 2, 4, 8, 16:
+		if s.rowFormat != "COMPRESSED" {
+			return s, fmt.Errorf("keyBlockSize needs rowFormat COMPRESSED")
+		}
+	default:
+		return s, fmt.Errorf("keyBlockSize must be 1, 2, 4, 8 or 16")
+	}
+	switch s.compression = strings.ToLower(cfg.Schema.Setting("compression", "")); s.compression {
+	case "", "none", "zlib", "lz4":
+	default:
+		return s, fmt.Errorf("unknown compression %q (want zlib, lz4 or none)", s.compression)
+	}
+	if s.compression != "" && s.compression != "none" && s.rowFormat == "COMPRESSED" {
+		return s, fmt.Errorf("page compression does not combine with rowFormat COMPRESSED")
+	}
+	return s, nil
+}
+
+// keyColumn is the primary key column.
+func (s schema) keyColumn() string {
+	if s.uuid {
+		return "uuid_key"
+	}
+	return "id"
+}
+
+// key is the primary key value of id.
+func (s schema) key(id int64) any {
+	if s.uuid {
+		u := workload.UUID(id)
+		return u[:]
+	}
+	return id
+}
+
+// columns are the columns rowArgs fills, in order.
+func (s schema) columns() string {
+	if s.uuid {
+		return "id, experiment_name, targeting_rules, uuid_key"
+	}
+	return "id, experiment_name, targeting_rules"
+}
+
+// rowArgs appends the values of a row for an insert of columns to args.
+func (s schema) rowArgs(args []any, rule conf.ExperimentRule) []any {
+	args = append(args, rule.ID, rule.ExperimentName, rule.TargetingRules)
+	if s.uuid {
+		args = append(args, s.key(rule.ID))
+	}
+	return args
+}
+
+// placeholders are the parameters of an insert of one row of columns.
+func (s schema) placeholders() string {
+	if s.uuid {
+		return "(?, ?, ?, ?)"
+	}
+	return "(?, ?, ?)"
+}
+
+// ddl is the statement that creates the table.
+func (s schema) ddl(table string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE TABLE IF NOT EXISTS %s (\n", table)
+	if s.uuid {
+		sb.WriteString("\tuuid_key BINARY(16) PRIMARY KEY,\n\tid BIGINT NOT NULL,\n")
+	} else {
+		sb.WriteString("\tid BIGINT PRIMARY KEY,\n")
+	}
+	sb.WriteString("\texperiment_name TEXT,\n\ttargeting_rules JSON\n)")
+	if s.rowFormat != "" {
+		sb.WriteString(" ROW_FORMAT=" + s.rowFormat)
+	}
+	if s.keyBlockSize != 0 {
+		fmt.Fprintf(&sb, " KEY_BLOCK_SIZE=%d", s.keyBlockSize)
+	}
+	if s.compression != "" {
+		// Page compression needs a file system with hole punching.
+		fmt.Fprintf(&sb, " COMPRESSION='%s'", s.compression)
+	}
+	return sb.String()
+}
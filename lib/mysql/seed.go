@@ -2,49 +2,112 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"db-bench/lib/conf"
+	"db-bench/lib/seed"
+	"db-bench/lib/workload"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
 )
 
+// maxPlaceholders is the most parameters a MySQL prepared statement takes.
+const maxPlaceholders = 65535
+
+// checkBatchSize rejects a seed.batchSize whose multi-row INSERT would need
+// more than maxPlaceholders parameters.
+func checkBatchSize(cfg *conf.Config, s schema) error {
+	perRow := len(s.rowArgs(nil, conf.ExperimentRule{}))
+	if limit := maxPlaceholders / perRow; cfg.Seed.BatchSize > limit {
+		return fmt.Errorf("mysql.seed.batchSize %d exceeds %d: MySQL takes at most %d placeholders per statement",
+			cfg.Seed.BatchSize, limit, maxPlaceholders)
+	}
+	return nil
+}
+
 func (t *MySQLTester) Seed(ctx context.Context) error {
-	createTableSQL := fmt.Sprintf(`
-		CREATE TABLE IF NOT EXISTS %s (
-			id BIGINT PRIMARY KEY,
-			experiment_name TEXT,
-			targeting_rules JSON
-		)`, t.cfg.TableName)
-
-	if _, err := t.db.ExecContext(ctx, createTableSQL); err != nil {
+	if err := checkBatchSize(t.cfg, t.schema); err != nil {
+		return err
+	}
+	if _, err := t.db.ExecContext(ctx, t.schema.ddl(t.cfg.TableName)); err != nil {
 		return err
 	}
 
-	log.Println("MySQL: Writing rows...")
+	err := seed.Run(ctx, "MySQL", t.cfg, t, func(ctx context.Context, rows []conf.ExperimentRule) error {
+		// One multi-row INSERT per batch; the statement is prepared once per batch size.
+		query := fmt.Sprintf("INSERT IGNORE INTO %s (%s) VALUES %s", t.cfg.TableName, t.schema.columns(),
+			strings.TrimSuffix(strings.Repeat(t.schema.placeholders()+", ", len(rows)), ", "))
+		args := make([]any, 0, 4*len(rows))
+		for _, rule := range rows {
+			args = t.schema.rowArgs(args, rule)
+		}
+		_, err := t.exec(ctx, query, args...)
+		return err
+	})
+	if err != nil || !t.cfg.Query.Index {
+		return err
+	}
+	return t.indexAttribute(ctx)
+}
 
-	// Prepare statement for better performance
-	stmt, err := t.db.PrepareContext(ctx,
-		fmt.Sprintf("INSERT IGNORE INTO %s (id, experiment_name, targeting_rules) VALUES (?, ?, ?)", t.cfg.TableName))
+// indexAttribute adds a virtual column extracting query.attribute and
+// indexes it; MySQL cannot index inside a JSON column directly. The column
+// is named after the attribute, so each attribute gets its own.
+func (t *MySQLTester) indexAttribute(ctx context.Context) error {
+	column := "rules_" + strings.Join(t.cfg.Query.Path(), "_")
+	log.Printf("MySQL: Indexing targeting_rules.%s as generated column %s...", t.cfg.Query.Attribute, column)
+	// The collation must be utf8mb4_bin, that of ->>, for the optimizer to
+	// use the index in queries on the JSON expression.
+	alter := fmt.Sprintf(`
+		ALTER TABLE %s
+			ADD COLUMN %s VARCHAR(255) COLLATE utf8mb4_bin AS (targeting_rules->>'$.%s') VIRTUAL,
+			ADD INDEX %s_idx (%s)`, t.cfg.TableName, column, t.cfg.Query.Attribute, column, column)
+	_, err := t.db.ExecContext(ctx, alter)
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == 1060 { // duplicate column: indexed by a previous seed
+		return nil
+	}
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to index %s: %w", t.cfg.Query.Attribute, err)
 	}
-	defer stmt.Close()
+	return nil
+}
 
-	for i := 1; i <= t.cfg.RecordCount; i++ {
-		rule := conf.ExperimentRule{
-			ID:             int64(i),
-			ExperimentName: fmt.Sprintf("Test %d", i),
-			TargetingRules: `{"country":"US"}`,
-		}
+func (t *MySQLTester) Count(ctx context.Context) (int64, error) {
+	var n int64
+	err := t.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", t.cfg.TableName)).Scan(&n)
+	return n, err
+}
 
-		_, err := stmt.ExecContext(ctx, rule.ID, rule.ExperimentName, rule.TargetingRules)
-		if err != nil {
-			log.Printf("Warning: MySQL insert failed for key %d: %v", i, err)
-		}
+func (t *MySQLTester) MaxID(ctx context.Context) (int64, error) {
+	var id int64
+	err := t.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COALESCE(MAX(id), 0) FROM %s", t.cfg.TableName)).Scan(&id)
+	return id, err
+}
 
-		if i%10000 == 0 {
-			log.Printf("MySQL: %d records prepared...", i)
-		}
+func (t *MySQLTester) Get(ctx context.Context, id int64) (conf.ExperimentRule, error) {
+	rule := conf.ExperimentRule{ID: id}
+	stmt, err := t.stmt(ctx, t.q.get)
+	if err != nil {
+		return rule, err
 	}
+	err = stmt.QueryRowContext(ctx, t.schema.key(id)).Scan(&rule.ExperimentName, &rule.TargetingRules)
+	if errors.Is(err, sql.ErrNoRows) {
+		return rule, workload.ErrNotFound
+	}
+	return rule, err
+}
 
-	return nil
+func (t *MySQLTester) Drop(ctx context.Context) error {
+	// Statements prepared against the old table would fail once it is gone.
+	t.stmts.Range(func(query, s any) bool {
+		s.(*sql.Stmt).Close()
+		t.stmts.Delete(query)
+		return true
+	})
+	_, err := t.db.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", t.cfg.TableName))
+	return err
 }
@@ -4,32 +4,41 @@ import (
 	"context"
 	"database/sql"
 	"db-bench/lib/conf"
-	_ "github.com/go-sql-driver/mysql"
+	"fmt"
+	"sync"
 )
 
 type MySQLTester struct {
-	db  *sql.DB
-	cfg *conf.Config
+	db     *sql.DB
+	cfg    *conf.Config
+	schema schema
+	q      queries
+	stmts  sync.Map
 }
 
 func NewMySQLTester(ctx context.Context, cfg *conf.Config) (*MySQLTester, error) {
-	db, err := sql.Open("mysql", cfg.URI)
+	s, err := newSchema(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("mysql.schemas.%s: %w", cfg.Schema.Name, err)
+	}
+	db, err := open(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	db.SetMaxOpenConns(cfg.WorkerCount + 10)
-	db.SetMaxIdleConns(cfg.WorkerCount / 2)
-
 	// Test connection
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
 		return nil, err
 	}
 
-	return &MySQLTester{db: db, cfg: cfg}, nil
+	return &MySQLTester{db: db, cfg: cfg, schema: s, q: newQueries(cfg.TableName, cfg.Query.Attribute, s)}, nil
 }
 
 func (t *MySQLTester) Close() {
+	t.stmts.Range(func(_, s any) bool {
+		s.(*sql.Stmt).Close()
+		return true
+	})
 	t.db.Close()
 }
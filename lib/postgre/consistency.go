@@ -0,0 +1,17 @@
+package postgre
+
+import "db-bench/lib/backend"
+
+var consistencyLevels = []backend.ConfigKey{
+	{Key: "read_committed", Description: "isolation level READ COMMITTED (default)"},
+	{Key: "repeatable_read", Description: "isolation level REPEATABLE READ"},
+	{Key: "serializable", Description: "isolation level SERIALIZABLE"},
+}
+
+// isolationLevels maps the consistency key onto default_transaction_isolation,
+// which every statement of a session runs at.
+var isolationLevels = map[string]string{
+	"read_committed":  "read committed",
+	"repeatable_read": "repeatable read",
+	"serializable":    "serializable",
+}
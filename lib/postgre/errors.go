@@ -0,0 +1,33 @@
+package postgre
+
+import (
+	"errors"
+	"strings"
+
+	"db-bench/lib/workload"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func (t *PostgresTester) ClassifyError(err error) string {
+	var pgErr *pgconn.PgError
+	var connErr *pgconn.ConnectError
+	switch {
+	case errors.As(err, &pgErr):
+		switch {
+		case pgErr.Code == "57014": // query_canceled, e.g. by statement_timeout
+			return workload.ReasonTimeout
+		case pgErr.Code == "53300", pgErr.Code == "53400": // too_many_connections, configuration_limit_exceeded
+			return workload.ReasonThrottled
+		case strings.HasPrefix(pgErr.Code, "08"), strings.HasPrefix(pgErr.Code, "57P"): // connection_exception, shutdown
+			return workload.ReasonUnavailable
+		case pgErr.Code == "22P02", pgErr.Code == "22032": // invalid_text_representation, invalid_json_text
+			return workload.ReasonDecode
+		}
+	case errors.As(err, &connErr):
+		return workload.ReasonUnavailable
+	case pgconn.Timeout(err):
+		return workload.ReasonTimeout
+	}
+	return ""
+}
@@ -0,0 +1,178 @@
+package postgre
+
+import (
+	"context"
+	"db-bench/lib/conf"
+	"db-bench/lib/workload"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type queries struct {
+	read, get, update, insert, delete string
+	readMany, getMany                 string
+	// by scan mode; $1, $2 are from, to for ranges and from or offset, limit for pages
+	scanIDs, scanRows map[string]string
+	// $1 is a jsonb document targeting_rules must contain, $2 the limit
+	queryIDs, queryRows string
+}
+
+func newQueries(table string, s schema) queries {
+	key := s.keyColumn()
+	return queries{
+		read:   fmt.Sprintf("SELECT id FROM %s WHERE %s = $1", table, key),
+		get:    fmt.Sprintf("SELECT experiment_name, targeting_rules::text FROM %s WHERE %s = $1", table, key),
+		update: fmt.Sprintf("UPDATE %s SET experiment_name = $2, targeting_rules = $3 WHERE %s = $1", table, key),
+		insert: fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, s.columns(), s.placeholders()),
+		delete: fmt.Sprintf("DELETE FROM %s WHERE %s = $1", table, key),
+
+		readMany: fmt.Sprintf("SELECT id FROM %s WHERE %s = ANY($1)", table, key),
+		getMany:  fmt.Sprintf("SELECT id, experiment_name, targeting_rules::text FROM %s WHERE %s = ANY($1)", table, key),
+
+		scanIDs:  scanQueries(table, key, "id"),
+		scanRows: scanQueries(table, key, "id, experiment_name, targeting_rules::text"),
+
+		// @> is served by the GIN index Seed creates with query.index.
+		queryIDs:  fmt.Sprintf("SELECT id FROM %s WHERE targeting_rules @> $1::jsonb LIMIT $2", table),
+		queryRows: fmt.Sprintf("SELECT id, experiment_name, targeting_rules::text FROM %s WHERE targeting_rules @> $1::jsonb LIMIT $2", table),
+	}
+}
+
+// scanQueries page in key order; with UUID keys that order is random, and
+// ranges of ids are not contiguous.
+func scanQueries(table, key, columns string) map[string]string {
+	return map[string]string{
+		conf.ScanRange:  fmt.Sprintf("SELECT %s FROM %s WHERE id >= $1 AND id < $2 ORDER BY id", columns, table),
+		conf.ScanKeyset: fmt.Sprintf("SELECT %s FROM %s WHERE %s > $1 ORDER BY %s LIMIT $2", columns, table, key, key),
+		conf.ScanOffset: fmt.Sprintf("SELECT %s FROM %s ORDER BY %s OFFSET $1 LIMIT $2", columns, table, key),
+	}
+}
+
+func (t *PostgresTester) Read(ctx context.Context, id int64) error {
+	if t.cfg.Read.Projection != conf.ProjectionID {
+		return workload.ReadRow(ctx, t.cfg.Read.Projection, id, t.Get)
+	}
+	var idRead int64
+	err := t.pool.QueryRow(ctx, t.q.read, t.schema.key(id)).Scan(&idRead)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return workload.ErrNotFound
+	}
+	return err
+}
+
+func (t *PostgresTester) ReadMany(ctx context.Context, ids []int64) error {
+	query := t.q.getMany
+	if t.cfg.Read.Projection == conf.ProjectionID {
+		query = t.q.readMany
+	}
+	rows, err := t.pool.Query(ctx, query, t.schema.keys(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := 0
+	for rows.Next() {
+		found++
+		if t.cfg.Read.Projection == conf.ProjectionID {
+			continue
+		}
+		var rule conf.ExperimentRule
+		if err := rows.Scan(&rule.ID, &rule.ExperimentName, &rule.TargetingRules); err != nil {
+			return err
+		}
+		if err := workload.DecodeRow(t.cfg.Read.Projection, rule); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return workload.Found(found, len(ids))
+}
+
+func (t *PostgresTester) Scan(ctx context.Context, q workload.ScanQuery) (int, int64, error) {
+	query := t.q.scanRows[q.Mode]
+	if t.cfg.Read.Projection == conf.ProjectionID {
+		query = t.q.scanIDs[q.Mode]
+	}
+	var args []any
+	switch q.Mode {
+	case conf.ScanRange:
+		args = []any{q.From, q.To()}
+	case conf.ScanKeyset:
+		args = []any{t.schema.key(q.From), q.Limit}
+	default:
+		args = []any{q.Offset, q.Limit}
+	}
+	rows, err := t.pool.Query(ctx, query, args...)
+	if err != nil {
+		return 0, 0, err
+	}
+	return t.readRows(rows)
+}
+
+func (t *PostgresTester) Query(ctx context.Context, value any) (int, error) {
+	query := t.q.queryRows
+	if t.cfg.Read.Projection == conf.ProjectionID {
+		query = t.q.queryIDs
+	}
+	doc, err := json.Marshal(workload.Nested(t.cfg.Query.Path(), value))
+	if err != nil {
+		return 0, err
+	}
+	rows, err := t.pool.Query(ctx, query, string(doc), t.cfg.Query.Limit)
+	if err != nil {
+		return 0, err
+	}
+	n, _, err := t.readRows(rows)
+	return n, err
+}
+
+// readRows consumes rows of the scan or query columns the projection asks
+// for, returning their number and the last id.
+func (t *PostgresTester) readRows(rows pgx.Rows) (int, int64, error) {
+	defer rows.Close()
+	var err error
+	n := 0
+	var rule conf.ExperimentRule
+	for rows.Next() {
+		if t.cfg.Read.Projection == conf.ProjectionID {
+			err = rows.Scan(&rule.ID)
+		} else {
+			err = rows.Scan(&rule.ID, &rule.ExperimentName, &rule.TargetingRules)
+		}
+		if err == nil {
+			err = workload.DecodeRow(t.cfg.Read.Projection, rule)
+		}
+		if err != nil {
+			return n, rule.ID, err
+		}
+		n++
+	}
+	return n, rule.ID, rows.Err()
+}
+
+func (t *PostgresTester) Update(ctx context.Context, rule conf.ExperimentRule) error {
+	tag, err := t.pool.Exec(ctx, t.q.update, t.schema.key(rule.ID), rule.ExperimentName, rule.TargetingRules)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return workload.ErrNotFound
+	}
+	return nil
+}
+
+func (t *PostgresTester) Insert(ctx context.Context, rule conf.ExperimentRule) error {
+	_, err := t.pool.Exec(ctx, t.q.insert, t.schema.rowArgs(rule)...)
+	return err
+}
+
+func (t *PostgresTester) Delete(ctx context.Context, id int64) error {
+	_, err := t.pool.Exec(ctx, t.q.delete, t.schema.key(id))
+	return err
+}
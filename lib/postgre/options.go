@@ -0,0 +1,66 @@
+package postgre
+
+import (
+	"fmt"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var driverOptions = []backend.ConfigKey{
+	{Key: "maxConns", Description: "pool size (pgx default: 4 or the number of CPUs, whichever is larger)"},
+	{Key: "minConns", Description: "connections kept open while idle"},
+	{Key: "maxConnLifetime", Description: "close connections older than this (default 1h)"},
+	{Key: "maxConnIdleTime", Description: "close connections idle for this long (default 30m)"},
+	{Key: "healthCheckPeriod", Description: "interval of idle connection checks (default 1m)"},
+	{Key: "queryExecMode", Description: "cache_statement (default), cache_describe, describe_exec, exec or simple_protocol"},
+}
+
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// poolConfig parses the URI and applies postgres.options on top of it.
+func poolConfig(cfg *conf.Config) (*pgxpool.Config, error) {
+	pc, err := pgxpool.ParseConfig(cfg.URI)
+	if err != nil {
+		return nil, err
+	}
+	o := cfg.Options
+	maxConns, err := o.Int("maxConns", int(pc.MaxConns))
+	if err != nil {
+		return nil, err
+	}
+	minConns, err := o.Int("minConns", int(pc.MinConns))
+	if err != nil {
+		return nil, err
+	}
+	if maxConns < 1 || minConns < 0 || minConns > maxConns {
+		return nil, fmt.Errorf("need 0 <= minConns <= maxConns and maxConns >= 1")
+	}
+	pc.MaxConns, pc.MinConns = int32(maxConns), int32(minConns)
+	if pc.MaxConnLifetime, err = o.Duration("maxConnLifetime", pc.MaxConnLifetime); err != nil {
+		return nil, err
+	}
+	if pc.MaxConnIdleTime, err = o.Duration("maxConnIdleTime", pc.MaxConnIdleTime); err != nil {
+		return nil, err
+	}
+	if pc.HealthCheckPeriod, err = o.Duration("healthCheckPeriod", pc.HealthCheckPeriod); err != nil {
+		return nil, err
+	}
+	if mode, ok := o["queryExecMode"]; ok {
+		m, ok := queryExecModes[mode]
+		if !ok {
+			return nil, fmt.Errorf("unknown queryExecMode %q", mode)
+		}
+		pc.ConnConfig.DefaultQueryExecMode = m
+	}
+	return pc, nil
+}
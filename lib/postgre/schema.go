@@ -0,0 +1,138 @@
+package postgre
+
+import (
+	"fmt"
+	"strings"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+	"db-bench/lib/workload"
+)
+
+var schemaSettings = []backend.ConfigKey{
+	{Key: "keyType", Description: "bigint (default) or uuid: primary key on a UUID derived from the id"},
+	{Key: "fillfactor", Description: "table fillfactor, 10..100 (default 100)"},
+	{Key: "unlogged", Description: "true: UNLOGGED table, written without WAL"},
+	{Key: "compression", Description: "targeting_rules TOAST compression: pglz (default), lz4 or none"},
+}
+
+// schema is the table layout a schema variant selects.
+type schema struct {
+	// uuid keys the table on uuid_key, a UUID derived from id (workload.UUID);
+	// id stays a plain column.
+	uuid        bool
+	fillfactor  int
+	unlogged    bool
+	compression string
+}
+
+func newSchema(cfg *conf.Config) (schema, error) {
+	var s schema
+	var err error
+	switch keyType := cfg.Schema.Setting("keyType", "bigint"); keyType {
+	case "bigint":
+	case "uuid":
+		s.uuid = true
+		if cfg.Workload.Scan > 0 && cfg.Scan.Mode == conf.ScanRange {
+			return s, fmt.Errorf("scan.mode range needs bigint keys")
+		}
+	default:
+		return s, fmt.Errorf("unknown keyType %q (want bigint or uuid)", keyType)
+	}
+	if s.fillfactor, err = cfg.Schema.Int("fillfactor", 0); err != nil {
+		return s, err
+	}
+	if s.fillfactor != 0 && (s.fillfactor < 10 || s.fillfactor > 100) {
+		return s, fmt.Errorf("fillfactor must be between 10 and 100")
+	}
+	if s.unlogged, err = cfg.Schema.Bool("unlogged", false); err != nil {
+		return s, err
+	}
+	switch s.compression = cfg.Schema.Setting("compression", ""); s.compression {
+	case "", "pglz", "lz4", "none":
+	default:
+		return s, fmt.Errorf("unknown compression %q (want pglz, lz4 or none)", s.compression)
+	}
+	return s, nil
+}
+
+// keyColumn is the primary key column.
+func (s schema) keyColumn() string {
+	if s.uuid {
+		return "uuid_key"
+	}
+	return "id"
+}
+
+// key is the primary key value of id.
+func (s schema) key(id int64) any {
+	if s.uuid {
+		return workload.UUID(id)
+	}
+	return id
+}
+
+// keys are the primary key values of ids.
+func (s schema) keys(ids []int64) any {
+	if !s.uuid {
+		return ids
+	}
+	keys := make([][16]byte, len(ids))
+	for i, id := range ids {
+		keys[i] = workload.UUID(id)
+	}
+	return keys
+}
+
+// columns are the columns rowArgs fills, in order.
+func (s schema) columns() string {
+	if s.uuid {
+		return "id, experiment_name, targeting_rules, uuid_key"
+	}
+	return "id, experiment_name, targeting_rules"
+}
+
+// rowArgs are the values of a row for an insert of columns.
+func (s schema) rowArgs(rule conf.ExperimentRule) []any {
+	if s.uuid {
+		return []any{rule.ID, rule.ExperimentName, rule.TargetingRules, workload.UUID(rule.ID)}
+	}
+	return []any{rule.ID, rule.ExperimentName, rule.TargetingRules}
+}
+
+// placeholders are the parameters of an insert of columns.
+func (s schema) placeholders() string {
+	if s.uuid {
+		return "$1, $2, $3, $4"
+	}
+	return "$1, $2, $3"
+}
+
+// ddl returns the statements that create the table.
+func (s schema) ddl(table string) []string {
+	var sb strings.Builder
+	sb.WriteString("CREATE ")
+	if s.unlogged {
+		sb.WriteString("UNLOGGED ")
+	}
+	fmt.Fprintf(&sb, "TABLE IF NOT EXISTS %s (\n", table)
+	if s.uuid {
+		sb.WriteString("\tuuid_key UUID PRIMARY KEY,\n\tid BIGINT NOT NULL,\n")
+	} else {
+		sb.WriteString("\tid BIGINT PRIMARY KEY,\n")
+	}
+	sb.WriteString("\texperiment_name TEXT,\n\ttargeting_rules JSONB")
+	if s.compression == "pglz" || s.compression == "lz4" {
+		sb.WriteString(" COMPRESSION " + s.compression)
+	}
+	sb.WriteString("\n)")
+	if s.fillfactor != 0 {
+		fmt.Fprintf(&sb, " WITH (fillfactor = %d)", s.fillfactor)
+	}
+	stmts := []string{sb.String()}
+	if s.compression == "none" {
+		// EXTERNAL moves large values out of line without compressing them.
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN targeting_rules SET STORAGE EXTERNAL", table))
+	}
+	return stmts
+}
@@ -3,33 +3,69 @@ package postgre
 import (
 	"context"
 	"db-bench/lib/conf"
+	"db-bench/lib/seed"
+	"db-bench/lib/workload"
+	"errors"
 	"fmt"
 	"log"
+
+	"github.com/jackc/pgx/v5"
 )
 
 func (t *PostgresTester) Seed(ctx context.Context) error {
-	createTableSQL := fmt.Sprintf(`
-		CREATE TABLE IF NOT EXISTS %s (
-			id BIGINT PRIMARY KEY,
-			experiment_name TEXT,
-			targeting_rules JSONB
-		)`, t.cfg.TableName)
-	if _, err := t.pool.Exec(ctx, createTableSQL); err != nil {
-		return err
-	}
-	log.Println("Postgres: Writing rows...")
-	for i := 1; i <= t.cfg.RecordCount; i++ {
-		rule := conf.ExperimentRule{ID: int64(i), ExperimentName: fmt.Sprintf("Test %d", i), TargetingRules: `{"country":"US"}`}
-		_, err := t.pool.Exec(ctx,
-			fmt.Sprintf("INSERT INTO %s (id, experiment_name, targeting_rules) VALUES ($1, $2, $3) ON CONFLICT (id) DO NOTHING", t.cfg.TableName),
-			rule.ID, rule.ExperimentName, rule.TargetingRules,
-		)
-		if err != nil {
-			log.Printf("Warning: Postgres insert failed for key %d: %v", i, err)
+	for _, stmt := range t.schema.ddl(t.cfg.TableName) {
+		if _, err := t.pool.Exec(ctx, stmt); err != nil {
+			return err
 		}
-		if i%10000 == 0 {
-			log.Printf("Postgres: %d records prepared...", i)
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO NOTHING",
+		t.cfg.TableName, t.schema.columns(), t.schema.placeholders(), t.schema.keyColumn())
+	// A pgx batch pipelines the inserts in a single round trip; unlike
+	// CopyFrom it tolerates rows left over from a previous seed.
+	err := seed.Run(ctx, "Postgres", t.cfg, t, func(ctx context.Context, rows []conf.ExperimentRule) error {
+		batch := &pgx.Batch{}
+		for _, rule := range rows {
+			batch.Queue(insert, t.schema.rowArgs(rule)...)
 		}
+		return t.pool.SendBatch(ctx, batch).Close()
+	})
+	if err != nil || !t.cfg.Query.Index {
+		return err
+	}
+	// Built after the load, which is much faster than maintaining it row by
+	// row. jsonb_path_ops serves containment (@>) only, but is smaller and
+	// faster than the default jsonb_ops.
+	log.Printf("Postgres: Creating GIN index on targeting_rules...")
+	index := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_rules_idx ON %s USING GIN (targeting_rules jsonb_path_ops)",
+		t.cfg.TableName, t.cfg.TableName)
+	if _, err := t.pool.Exec(ctx, index); err != nil {
+		return fmt.Errorf("failed to create GIN index: %w", err)
 	}
 	return nil
 }
+
+func (t *PostgresTester) Count(ctx context.Context) (int64, error) {
+	var n int64
+	err := t.pool.QueryRow(ctx, fmt.Sprintf("SELECT count(*) FROM %s", t.cfg.TableName)).Scan(&n)
+	return n, err
+}
+
+func (t *PostgresTester) MaxID(ctx context.Context) (int64, error) {
+	var id int64
+	err := t.pool.QueryRow(ctx, fmt.Sprintf("SELECT coalesce(max(id), 0) FROM %s", t.cfg.TableName)).Scan(&id)
+	return id, err
+}
+
+func (t *PostgresTester) Get(ctx context.Context, id int64) (conf.ExperimentRule, error) {
+	rule := conf.ExperimentRule{ID: id}
+	err := t.pool.QueryRow(ctx, t.q.get, t.schema.key(id)).Scan(&rule.ExperimentName, &rule.TargetingRules)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return rule, workload.ErrNotFound
+	}
+	return rule, err
+}
+
+func (t *PostgresTester) Drop(ctx context.Context) error {
+	_, err := t.pool.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", t.cfg.TableName))
+	return err
+}
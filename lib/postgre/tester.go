@@ -3,20 +3,35 @@ package postgre
 import (
 	"context"
 	"db-bench/lib/conf"
+	"fmt"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type PostgresTester struct {
-	pool *pgxpool.Pool
-	cfg  *conf.Config
+	pool   *pgxpool.Pool
+	cfg    *conf.Config
+	schema schema
+	q      queries
 }
 
 func NewPostgresTester(ctx context.Context, cfg *conf.Config) (*PostgresTester, error) {
-	pool, err := pgxpool.New(ctx, cfg.URI)
+	s, err := newSchema(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("postgres.schemas.%s: %w", cfg.Schema.Name, err)
+	}
+	pc, err := poolConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("postgres.options: %w", err)
+	}
+	if level, ok := isolationLevels[cfg.Consistency]; ok {
+		pc.ConnConfig.RuntimeParams["default_transaction_isolation"] = level
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, pc)
 	if err != nil {
 		return nil, err
 	}
-	return &PostgresTester{pool: pool, cfg: cfg}, nil
+	return &PostgresTester{pool: pool, cfg: cfg, schema: s, q: newQueries(cfg.TableName, s)}, nil
 }
 
 func (t *PostgresTester) Close() { t.pool.Close() }
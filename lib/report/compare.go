@@ -0,0 +1,182 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"db-bench/lib/stats"
+)
+
+// Thresholds decide when a difference between two runs counts as a regression.
+type Thresholds struct {
+	// relative throughput drop, e.g. 0.05 for 5%
+	ThroughputDrop float64
+	// relative p99 / p99.9 latency increase, e.g. 0.10 for 10%; the report
+	// only keeps run-wide percentiles, so latency has no significance test
+	LatencyIncrease float64
+	// absolute error rate increase, e.g. 0.001 for 0.1 percentage points
+	ErrorRateIncrease float64
+	// significance level for the per-second throughput t-test
+	Alpha float64
+}
+
+var DefaultThresholds = Thresholds{
+	ThroughputDrop:    0.05,
+	LatencyIncrease:   0.10,
+	ErrorRateIncrease: 0.001,
+	Alpha:             0.05,
+}
+
+// Delta is the change of one metric of one operation between two runs.
+type Delta struct {
+	Op         string
+	Metric     string
+	Base       float64
+	Candidate  float64
+	Change     float64 // relative change; absolute for error_rate
+	PValue     float64 // NaN when no significance test applies
+	Regression bool
+}
+
+type Comparison struct {
+	Base      *Report
+	Candidate *Report
+	Deltas    []Delta
+}
+
+func (c *Comparison) Regressed() bool {
+	for _, d := range c.Deltas {
+		if d.Regression {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads a report previously written with WriteJSON.
+func Load(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Compare reports the per-operation deltas of cand against base.
+// Operations that only exist in one of the runs are skipped.
+func Compare(base, cand *Report, th Thresholds) *Comparison {
+	c := &Comparison{Base: base, Candidate: cand}
+	candOps := map[string]OpSummary{}
+	for _, s := range append(cand.Operations, cand.Total) {
+		candOps[s.Op] = s
+	}
+
+	for _, b := range append(base.Operations, base.Total) {
+		n, ok := candOps[b.Op]
+		if !ok {
+			continue
+		}
+
+		tp := relDelta(b.Op, "throughput", b.Throughput, n.Throughput)
+		tp.PValue = math.NaN()
+		if b.Op == "total" {
+			// Only the total has a time series to test against.
+			_, tp.PValue = stats.WelchTTest(perSecond(base), perSecond(cand))
+		}
+		tp.Regression = -tp.Change > th.ThroughputDrop && (math.IsNaN(tp.PValue) || tp.PValue < th.Alpha)
+		c.Deltas = append(c.Deltas, tp)
+
+		errRate := Delta{Op: b.Op, Metric: "error_rate", Base: b.ErrorRate, Candidate: n.ErrorRate,
+			Change: n.ErrorRate - b.ErrorRate, PValue: math.NaN()}
+		errRate.Regression = errRate.Change > th.ErrorRateIncrease
+		c.Deltas = append(c.Deltas, errRate)
+
+		for _, m := range []struct {
+			name       string
+			base, cand float64
+			tail       bool
+		}{
+			{"p50_ms", b.Latency.P50, n.Latency.P50, false},
+			{"p90_ms", b.Latency.P90, n.Latency.P90, false},
+			{"p99_ms", b.Latency.P99, n.Latency.P99, true},
+			{"p999_ms", b.Latency.P999, n.Latency.P999, true},
+			{"max_ms", b.Latency.Max, n.Latency.Max, false},
+		} {
+			d := relDelta(b.Op, m.name, m.base, m.cand)
+			d.PValue = math.NaN()
+			d.Regression = m.tail && d.Change > th.LatencyIncrease
+			c.Deltas = append(c.Deltas, d)
+		}
+	}
+	return c
+}
+
+func relDelta(op, metric string, base, cand float64) Delta {
+	d := Delta{Op: op, Metric: metric, Base: base, Candidate: cand}
+	if base != 0 {
+		d.Change = (cand - base) / base
+	}
+	return d
+}
+
+// perSecond returns successful ops per second, without the partial first
+// and last seconds of the run.
+func perSecond(r *Report) []float64 {
+	ts := r.TimeSeries
+	if len(ts) > 2 {
+		ts = ts[1 : len(ts)-1]
+	}
+	xs := make([]float64, len(ts))
+	for i, s := range ts {
+		xs[i] = float64(s.Ops - s.Errors)
+	}
+	return xs
+}
+
+func (c *Comparison) Write(w io.Writer) {
+	for _, r := range []struct {
+		label string
+		rep   *Report
+	}{{"base:     ", c.Base}, {"candidate:", c.Candidate}} {
+		aborted := ""
+		if r.rep.Aborted {
+			aborted = ", aborted"
+		}
+		schema := ""
+		if sc := r.rep.Config.Schema; sc.Name != "" {
+			schema = ", schema " + sc.String()
+		}
+		options := ""
+		if o := r.rep.Config.Options; len(o) > 0 {
+			options = ", options " + o.String()
+		}
+		if c := r.rep.Config.Consistency; c != "" {
+			options += ", consistency " + c
+		}
+		fmt.Fprintf(w, "%s %s %s (%.0fs%s%s%s)\n", r.label, r.rep.DB, r.rep.StartedAt.Format("2006-01-02 15:04:05"),
+			r.rep.DurationSec, aborted, schema, options)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-18s %-12s %14s %14s %10s %8s\n", "op", "metric", "base", "candidate", "change", "p")
+	for _, d := range c.Deltas {
+		change := fmt.Sprintf("%+.2f%%", 100*d.Change)
+		p := ""
+		if !math.IsNaN(d.PValue) {
+			p = fmt.Sprintf("%.4f", d.PValue)
+		}
+		flag := ""
+		if d.Regression {
+			flag = "  REGRESSION"
+		}
+		fmt.Fprintf(w, "%-18s %-12s %14.4f %14.4f %10s %8s%s\n", d.Op, d.Metric, d.Base, d.Candidate, change, p, flag)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "p: one-sided Welch t-test over the per-second total throughput; latency and error rate are flagged on thresholds alone")
+}
@@ -0,0 +1,364 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"db-bench/lib/conf"
+	"db-bench/lib/runner"
+	"db-bench/lib/stats"
+)
+
+// Report is the archived outcome of a single run.
+type Report struct {
+	DB          string      `json:"db"`
+	StartedAt   time.Time   `json:"startedAt"`
+	FinishedAt  time.Time   `json:"finishedAt"`
+	DurationSec float64     `json:"durationSec"`
+	Aborted     bool        `json:"aborted"`
+	Config      conf.Config `json:"config"`
+	Total       OpSummary   `json:"total"`
+	Operations  []OpSummary `json:"operations"`
+	TimeSeries  []Second    `json:"timeSeries"`
+	// Stages is only set for runs with a load profile.
+	Stages []StageSummary `json:"stages,omitempty"`
+	// Knee is the number of the last stage before the database saturated,
+	// or 0 if no stage showed saturation.
+	Knee int `json:"knee,omitempty"`
+	// FindMax is only set for find-max searches; the rest of the report then
+	// describes the fastest trial that met the SLO.
+	FindMax *FindMaxSummary `json:"findMax,omitempty"`
+	// Matrix is only set for consistency matrix runs; the rest of the report
+	// then describes the run of the first level.
+	Matrix []LevelSummary `json:"matrix,omitempty"`
+	// Validation is only set when reads were checked against the generator.
+	Validation *ValidationSummary `json:"validation,omitempty"`
+	// ErrorReasons counts the errors of each operation by reason.
+	ErrorReasons []ReasonSummary `json:"errorReasons,omitempty"`
+	// TopErrors are the most frequent error messages.
+	TopErrors []ErrorSummary `json:"topErrors,omitempty"`
+}
+
+type ReasonSummary struct {
+	Op     string `json:"op"`
+	Reason string `json:"reason"`
+	Count  int64  `json:"count"`
+}
+
+type ErrorSummary struct {
+	Op      string `json:"op"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Count   int64  `json:"count"`
+}
+
+type ValidationSummary struct {
+	Checked     int64               `json:"checked"`
+	Failed      int64               `json:"failed"`
+	FailureRate float64             `json:"failureRate"`
+	Examples    []ValidationExample `json:"examples,omitempty"`
+}
+
+// ValidationExample is a read that returned a missing or wrong row.
+type ValidationExample struct {
+	ID    int64     `json:"id"`
+	At    time.Time `json:"at"`
+	Error string    `json:"error"`
+}
+
+type FindMaxSummary struct {
+	// MaxOpsPerSec is the highest target rate that met the SLO, 0 if none did.
+	MaxOpsPerSec float64        `json:"maxOpsPerSec"`
+	Trials       []TrialSummary `json:"trials"`
+}
+
+type TrialSummary struct {
+	Trial           int       `json:"trial"`
+	TargetOpsPerSec float64   `json:"targetOpsPerSec"`
+	Passed          bool      `json:"passed"`
+	Reason          string    `json:"reason,omitempty"`
+	Total           OpSummary `json:"total"`
+}
+
+// LevelSummary is the outcome of one consistency level of a matrix run.
+type LevelSummary struct {
+	Consistency string      `json:"consistency"`
+	DurationSec float64     `json:"durationSec"`
+	Aborted     bool        `json:"aborted,omitempty"`
+	Total       OpSummary   `json:"total"`
+	Operations  []OpSummary `json:"operations"`
+	// ErrorReasons counts the errors of each operation by reason.
+	ErrorReasons []ReasonSummary `json:"errorReasons,omitempty"`
+}
+
+// op returns the summary of op, "total" for all operations.
+func (l LevelSummary) op(op string) (OpSummary, bool) {
+	if op == "total" {
+		return l.Total, true
+	}
+	for _, s := range l.Operations {
+		if s.Op == op {
+			return s, true
+		}
+	}
+	return OpSummary{}, false
+}
+
+type OpSummary struct {
+	Op         string  `json:"op"`
+	Count      int64   `json:"count"`
+	Errors     int64   `json:"errors"`
+	Throughput float64 `json:"throughput"`
+	ErrorRate  float64 `json:"errorRate"`
+	Latency    Latency `json:"latencyMs"`
+	// Keys, KeysPerSec and KeyLatency are only set for multi-key operations;
+	// KeyLatency is the latency of an operation divided by its keys.
+	Keys       int64    `json:"keys,omitempty"`
+	KeysPerSec float64  `json:"keysPerSec,omitempty"`
+	KeyLatency *Latency `json:"keyLatencyMs,omitempty"`
+}
+
+// Latency holds latency statistics in milliseconds.
+type Latency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	P999 float64 `json:"p999"`
+	Max  float64 `json:"max"`
+}
+
+// StageSummary is the outcome of one stage of a load profile.
+type StageSummary struct {
+	Stage           int       `json:"stage"`
+	Workers         int       `json:"workers"`
+	TargetOpsPerSec float64   `json:"targetOpsPerSec"`
+	DurationSec     float64   `json:"durationSec"`
+	Total           OpSummary `json:"total"`
+	// Efficiency is the relative throughput gain over the previous stage
+	// divided by the relative load gain; 0 when the load did not grow.
+	Efficiency float64 `json:"efficiency"`
+}
+
+// Second is one point of the per-second time series.
+type Second struct {
+	Second int     `json:"second"`
+	Ops    int64   `json:"ops"`
+	Errors int64   `json:"errors"`
+	MeanMs float64 `json:"meanMs"`
+	MaxMs  float64 `json:"maxMs"`
+}
+
+func New(cfg *conf.Config, res *runner.Result) *Report {
+	elapsed := res.End.Sub(res.Start).Seconds()
+	r := &Report{
+		DB:          cfg.DB,
+		StartedAt:   res.Start,
+		FinishedAt:  res.End,
+		DurationSec: elapsed,
+		Aborted:     res.Aborted,
+		Config:      cfg.Snapshot(),
+		Total:       summarize("total", res.Stats.Total(), elapsed),
+	}
+	for _, op := range res.Stats.Ops() {
+		r.Operations = append(r.Operations, summarize(op, res.Stats.Op(op), elapsed))
+	}
+	for i, iv := range res.Stats.Series() {
+		sec := Second{Second: i, Ops: iv.Ops, Errors: iv.Errors, MaxMs: ms(iv.Max)}
+		if iv.Ops > 0 {
+			sec.MeanMs = ms(iv.Sum / time.Duration(iv.Ops))
+		}
+		r.TimeSeries = append(r.TimeSeries, sec)
+	}
+	for i, st := range res.Stages {
+		d := st.End.Sub(st.Start).Seconds()
+		r.Stages = append(r.Stages, StageSummary{
+			Stage:           i + 1,
+			Workers:         st.Workers,
+			TargetOpsPerSec: st.TargetOpsPerSec,
+			DurationSec:     d,
+			Total:           summarize("total", st.Stats.Total(), d),
+		})
+	}
+	r.Knee = findKnee(r.Stages)
+	r.ErrorReasons, r.TopErrors = summarizeErrors(res.Errors)
+	if v := res.Validation; v != nil {
+		r.Validation = &ValidationSummary{Checked: v.Checked, Failed: v.Failed}
+		if v.Checked > 0 {
+			r.Validation.FailureRate = float64(v.Failed) / float64(v.Checked)
+		}
+		for _, e := range v.Examples {
+			r.Validation.Examples = append(r.Validation.Examples, ValidationExample{ID: e.ID, At: e.At, Error: e.Error})
+		}
+	}
+	return r
+}
+
+// Saturation thresholds for findKnee.
+const (
+	kneeMinEfficiency = 0.5
+	kneeP99Growth     = 2
+)
+
+// findKnee fills in the stage efficiencies and returns the number of the last
+// stage before adding load stopped paying off (0 if it never did): throughput
+// grew by less than half of the load increase, or p99 latency more than
+// doubled. The load of a stage is its target rate, or its worker count in a
+// closed loop. Stages without operations, such as one an interrupt cut off
+// as it started, say nothing about saturation and are skipped.
+func findKnee(stages []StageSummary) int {
+	knee := 0
+	for i := 1; i < len(stages); i++ {
+		prev, cur := stages[i-1], &stages[i]
+		if (prev.TargetOpsPerSec > 0) != (cur.TargetOpsPerSec > 0) {
+			continue
+		}
+		load := func(s StageSummary) float64 {
+			if s.TargetOpsPerSec > 0 {
+				return s.TargetOpsPerSec
+			}
+			return float64(s.Workers)
+		}
+		loadGain := load(*cur)/load(prev) - 1
+		if loadGain <= 0 || prev.Total.Throughput == 0 || cur.Total.Count == 0 {
+			continue
+		}
+		cur.Efficiency = (cur.Total.Throughput/prev.Total.Throughput - 1) / loadGain
+		saturated := cur.Efficiency < kneeMinEfficiency ||
+			(prev.Total.Latency.P99 > 0 && cur.Total.Latency.P99 > kneeP99Growth*prev.Total.Latency.P99)
+		if saturated && knee == 0 {
+			knee = prev.Stage
+		}
+	}
+	return knee
+}
+
+func summarize(op string, s *stats.OpStats, elapsed float64) OpSummary {
+	sum := OpSummary{Op: op, Count: s.Count, Errors: s.Errors}
+	if elapsed > 0 {
+		sum.Throughput = float64(s.Count-s.Errors) / elapsed
+	}
+	if s.Count > 0 {
+		sum.ErrorRate = float64(s.Errors) / float64(s.Count)
+	}
+	// Quantiles are bucket upper bounds, so keep them within the exact extremes.
+	q := func(v float64) float64 { return ms(min(max(s.Latency.Quantile(v), s.Min), s.Max)) }
+	sum.Latency = Latency{
+		Min:  ms(s.Min),
+		Mean: ms(s.Mean()),
+		P50:  q(0.50),
+		P90:  q(0.90),
+		P99:  q(0.99),
+		P999: q(0.999),
+		Max:  ms(s.Max),
+	}
+	if h := s.KeyLatency; h != nil {
+		sum.Keys = s.Keys
+		if elapsed > 0 {
+			sum.KeysPerSec = float64(s.Keys) / elapsed
+		}
+		sum.KeyLatency = &Latency{
+			Min:  ms(h.Quantile(0)),
+			Mean: ms(s.Sum / time.Duration(max(s.Keys, 1))),
+			P50:  ms(h.Quantile(0.50)),
+			P90:  ms(h.Quantile(0.90)),
+			P99:  ms(h.Quantile(0.99)),
+			P999: ms(h.Quantile(0.999)),
+			Max:  ms(h.Quantile(1)),
+		}
+	}
+	return sum
+}
+
+func ms(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+
+// NewFindMax builds the report of a find-max search from its best trial, or
+// from its last one when no trial met the SLO. It returns nil if no trial
+// completed.
+func NewFindMax(s *runner.Search) *Report {
+	if len(s.Trials) == 0 {
+		return nil
+	}
+	shown := s.Trials[len(s.Trials)-1]
+	if s.Best >= 0 {
+		shown = s.Trials[s.Best]
+	}
+	r := New(shown.Config, shown.Result)
+	r.Aborted = s.Aborted
+	r.FindMax = &FindMaxSummary{}
+	if s.Best >= 0 {
+		r.FindMax.MaxOpsPerSec = shown.TargetOpsPerSec
+	}
+	for i, t := range s.Trials {
+		elapsed := t.Result.End.Sub(t.Result.Start).Seconds()
+		r.FindMax.Trials = append(r.FindMax.Trials, TrialSummary{
+			Trial:           i + 1,
+			TargetOpsPerSec: t.TargetOpsPerSec,
+			Passed:          t.Passed,
+			Reason:          t.Reason,
+			Total:           summarize("total", t.Result.Stats.Total(), elapsed),
+		})
+	}
+	return r
+}
+
+// Level is the run of one consistency level of a matrix.
+type Level struct {
+	Config *conf.Config
+	Result *runner.Result
+}
+
+// NewMatrix builds the report of a matrix run from the run of its first
+// level and puts the summaries of all levels side by side. It returns nil if
+// no level ran.
+func NewMatrix(levels []Level) *Report {
+	if len(levels) == 0 {
+		return nil
+	}
+	r := New(levels[0].Config, levels[0].Result)
+	for _, l := range levels {
+		lr := New(l.Config, l.Result)
+		r.Aborted = r.Aborted || lr.Aborted
+		r.Matrix = append(r.Matrix, LevelSummary{
+			Consistency:  l.Config.Consistency,
+			DurationSec:  lr.DurationSec,
+			Aborted:      lr.Aborted,
+			Total:        lr.Total,
+			Operations:   lr.Operations,
+			ErrorReasons: lr.ErrorReasons,
+		})
+	}
+	return r
+}
+
+// maxTopErrors is how many error messages a report lists.
+const maxTopErrors = 10
+
+// summarizeErrors totals errs, which are sorted by count, per operation and
+// reason and picks the most frequent messages.
+func summarizeErrors(errs []runner.ErrorCount) ([]ReasonSummary, []ErrorSummary) {
+	var reasons []ReasonSummary
+	index := map[[2]string]int{}
+	var top []ErrorSummary
+	for _, e := range errs {
+		k := [2]string{e.Op, e.Reason}
+		i, ok := index[k]
+		if !ok {
+			i = len(reasons)
+			index[k] = i
+			reasons = append(reasons, ReasonSummary{Op: e.Op, Reason: e.Reason})
+		}
+		reasons[i].Count += e.Count
+		if len(top) < maxTopErrors {
+			top = append(top, ErrorSummary{Op: e.Op, Reason: e.Reason, Message: e.Message, Count: e.Count})
+		}
+	}
+	sort.SliceStable(reasons, func(i, j int) bool {
+		if reasons[i].Op != reasons[j].Op {
+			return reasons[i].Op < reasons[j].Op
+		}
+		return reasons[i].Count > reasons[j].Count
+	})
+	return reasons, top
+}
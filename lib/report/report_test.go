@@ -0,0 +1,54 @@
+package report
+
+import (
+	"math"
+	"testing"
+)
+
+// stage is a closed-loop stage with workers, throughput and p99 latency; a
+// zero throughput means the stage ran no operations at all.
+func stage(n, workers int, throughput, p99 float64) StageSummary {
+	s := StageSummary{Stage: n, Workers: workers}
+	if throughput > 0 {
+		s.Total = OpSummary{Op: "total", Count: int64(throughput * 10), Throughput: throughput, Latency: Latency{P99: p99}}
+	}
+	return s
+}
+
+func TestFindKnee(t *testing.T) {
+	for _, c := range []struct {
+		name       string
+		stages     []StageSummary
+		knee       int
+		efficiency []float64
+	}{
+		{"no stages", nil, 0, nil},
+		{"single stage", []StageSummary{stage(1, 10, 1000, 5)}, 0, []float64{0}},
+		{"linear", []StageSummary{stage(1, 10, 1000, 5), stage(2, 20, 2000, 5), stage(3, 40, 4000, 6)}, 0,
+			[]float64{0, 1, 1}},
+		{"flat load", []StageSummary{stage(1, 10, 1000, 5), stage(2, 10, 1000, 5)}, 0, []float64{0, 0}},
+		{"throughput plateau", []StageSummary{stage(1, 10, 1000, 5), stage(2, 20, 2000, 5), stage(3, 40, 2200, 6),
+			stage(4, 80, 2200, 9)}, 2, []float64{0, 1, 0.1, 0}},
+		{"p99 jump", []StageSummary{stage(1, 10, 1000, 5), stage(2, 20, 1900, 5), stage(3, 40, 3610, 11)}, 2,
+			[]float64{0, 0.9, 0.9}},
+		{"open loop", []StageSummary{
+			{Stage: 1, TargetOpsPerSec: 1000, Total: OpSummary{Count: 10000, Throughput: 1000}},
+			{Stage: 2, TargetOpsPerSec: 2000, Total: OpSummary{Count: 12000, Throughput: 1200}},
+		}, 1, []float64{0, 0.2}},
+		{"interrupted in the last stage", []StageSummary{stage(1, 10, 1000, 5), stage(2, 20, 2000, 5),
+			stage(3, 40, 0, 0)}, 0, []float64{0, 1, 0}},
+		{"no operations in the first stage", []StageSummary{stage(1, 10, 0, 0), stage(2, 20, 2000, 5),
+			stage(3, 40, 2100, 5)}, 2, []float64{0, 0, 0.05}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if knee := findKnee(c.stages); knee != c.knee {
+				t.Errorf("knee = %d, want %d", knee, c.knee)
+			}
+			for i, s := range c.stages {
+				if math.Abs(s.Efficiency-c.efficiency[i]) > 1e-9 {
+					t.Errorf("stage %d: efficiency = %g, want %g", s.Stage, s.Efficiency, c.efficiency[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Write stores the report in dir in each of the given formats and returns
+// the paths of the written files.
+func (r *Report) Write(dir string, formats []string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%s-%s", r.DB, r.StartedAt.Format("20060102-150405")))
+
+	var paths []string
+	for _, format := range formats {
+		var err error
+		switch format {
+		case "json":
+			err = writeFile(base+".json", r.WriteJSON)
+			paths = append(paths, base+".json")
+		case "csv":
+			err = writeFile(base+".csv", r.WriteCSV)
+			paths = append(paths, base+".csv")
+			if err == nil {
+				err = writeFile(base+"-timeseries.csv", r.WriteTimeSeriesCSV)
+				paths = append(paths, base+"-timeseries.csv")
+			}
+			if err == nil && len(r.Stages) > 0 {
+				err = writeFile(base+"-stages.csv", r.WriteStagesCSV)
+				paths = append(paths, base+"-stages.csv")
+			}
+			if err == nil && r.FindMax != nil {
+				err = writeFile(base+"-trials.csv", r.WriteTrialsCSV)
+				paths = append(paths, base+"-trials.csv")
+			}
+			if err == nil && len(r.Matrix) > 0 {
+				err = writeFile(base+"-matrix.csv", r.WriteMatrixCSV)
+				paths = append(paths, base+"-matrix.csv")
+			}
+		case "md":
+			err = writeFile(base+".md", r.WriteMarkdown)
+			paths = append(paths, base+".md")
+		default:
+			err = fmt.Errorf("unknown report format %q", format)
+		}
+		if err != nil {
+			return paths, err
+		}
+	}
+	return paths, nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+var summaryHeader = []string{"op", "count", "errors", "throughput", "error_rate",
+	"min_ms", "mean_ms", "p50_ms", "p90_ms", "p99_ms", "p999_ms", "max_ms"}
+
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(summaryHeader)
+	for _, s := range append(r.Operations, r.Total) {
+		l := s.Latency
+		cw.Write([]string{s.Op, strconv.FormatInt(s.Count, 10), strconv.FormatInt(s.Errors, 10),
+			f(s.Throughput), f(s.ErrorRate),
+			f(l.Min), f(l.Mean), f(l.P50), f(l.P90), f(l.P99), f(l.P999), f(l.Max)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r *Report) WriteTimeSeriesCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"second", "ops", "errors", "mean_ms", "max_ms"})
+	for _, s := range r.TimeSeries {
+		cw.Write([]string{strconv.Itoa(s.Second), strconv.FormatInt(s.Ops, 10), strconv.FormatInt(s.Errors, 10),
+			f(s.MeanMs), f(s.MaxMs)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r *Report) WriteStagesCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"stage", "workers", "target_ops", "duration_s", "count", "errors", "throughput",
+		"error_rate", "p50_ms", "p99_ms", "efficiency", "knee"})
+	for _, s := range r.Stages {
+		t := s.Total
+		cw.Write([]string{strconv.Itoa(s.Stage), strconv.Itoa(s.Workers), f(s.TargetOpsPerSec), f(s.DurationSec),
+			strconv.FormatInt(t.Count, 10), strconv.FormatInt(t.Errors, 10), f(t.Throughput),
+			f(t.ErrorRate), f(t.Latency.P50), f(t.Latency.P99), f(s.Efficiency),
+			strconv.FormatBool(r.Knee == s.Stage)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r *Report) WriteTrialsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"trial", "target_ops", "passed", "reason", "count", "errors", "throughput",
+		"error_rate", "p50_ms", "p99_ms", "p999_ms"})
+	for _, t := range r.FindMax.Trials {
+		s := t.Total
+		cw.Write([]string{strconv.Itoa(t.Trial), f(t.TargetOpsPerSec), strconv.FormatBool(t.Passed), t.Reason,
+			strconv.FormatInt(s.Count, 10), strconv.FormatInt(s.Errors, 10), f(s.Throughput),
+			f(s.ErrorRate), f(s.Latency.P50), f(s.Latency.P99), f(s.Latency.P999)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r *Report) WriteMatrixCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"consistency", "op", "count", "errors", "throughput", "error_rate",
+		"p50_ms", "p99_ms", "p999_ms", "aborted"})
+	for _, l := range r.Matrix {
+		for _, s := range append(l.Operations, l.Total) {
+			cw.Write([]string{l.Consistency, s.Op, strconv.FormatInt(s.Count, 10), strconv.FormatInt(s.Errors, 10),
+				f(s.Throughput), f(s.ErrorRate), f(s.Latency.P50), f(s.Latency.P99), f(s.Latency.P999),
+				strconv.FormatBool(l.Aborted)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	p := func(format string, args ...any) { fmt.Fprintf(w, format, args...) }
+
+	p("# %s benchmark report\n\n", r.DB)
+	p("- Started: %s\n", r.StartedAt.Format(time.RFC3339))
+	p("- Finished: %s\n", r.FinishedAt.Format(time.RFC3339))
+	p("- Duration: %.1fs\n", r.DurationSec)
+	if sc := r.Config.Schema; sc.Name != "" {
+		p("- Schema: %s\n", sc)
+	}
+	if o := r.Config.Options; len(o) > 0 {
+		p("- Driver options: %s\n", o)
+	}
+	if c := r.Config.Consistency; c != "" {
+		p("- Consistency: %s\n", c)
+	}
+	if r.Aborted {
+		p("- **Aborted**: the run was interrupted, results are partial\n")
+	}
+	p("\n")
+
+	if fm := r.FindMax; fm != nil {
+		slo := r.Config.FindMax.SLO
+		p("## Max throughput at SLO\n\n")
+		p("SLO: p99 <= %v, error rate <= %.4f%%, throughput >= %.0f%% of target.\n\n",
+			slo.P99, 100*slo.ErrorRate, 100*slo.MinThroughputRatio)
+		if fm.MaxOpsPerSec > 0 {
+			p("**%.0f ops/s** is the highest target rate that met the SLO; the sections below describe that trial.\n\n", fm.MaxOpsPerSec)
+		} else {
+			p("**No trial met the SLO**; the sections below describe the last trial.\n\n")
+		}
+		p("| trial | target ops/s | result | ops/s | error rate | p50 | p99 | p99.9 |\n")
+		p("|---:|---:|---|---:|---:|---:|---:|---:|\n")
+		for _, t := range fm.Trials {
+			result := "pass"
+			if !t.Passed {
+				result = "fail: " + t.Reason
+			}
+			s := t.Total
+			p("| %d | %.0f | %s | %.1f | %.4f%% | %.3f | %.3f | %.3f |\n",
+				t.Trial, t.TargetOpsPerSec, result, s.Throughput, 100*s.ErrorRate, s.Latency.P50, s.Latency.P99, s.Latency.P999)
+		}
+		p("\n")
+	}
+
+	if len(r.Matrix) > 0 {
+		p("## Consistency matrix\n\n")
+		p("Each level ran the same workload in turn; the sections below describe the %s run.\n\n", r.Matrix[0].Consistency)
+		p("| op | consistency | ops/s | error rate | p50 | p99 | p99.9 |\n")
+		p("|---|---|---:|---:|---:|---:|---:|\n")
+		// Group by operation so the levels of each sit next to each other.
+		var ops []string
+		for _, s := range r.Matrix[0].Operations {
+			ops = append(ops, s.Op)
+		}
+		for _, op := range append(ops, "total") {
+			for _, l := range r.Matrix {
+				s, ok := l.op(op)
+				if !ok {
+					continue
+				}
+				level := l.Consistency
+				if l.Aborted {
+					level += " (aborted)"
+				}
+				p("| %s | %s | %.1f | %.4f%% | %.3f | %.3f | %.3f |\n",
+					op, level, s.Throughput, 100*s.ErrorRate, s.Latency.P50, s.Latency.P99, s.Latency.P999)
+			}
+		}
+		p("\nLatencies are in milliseconds.\n\n")
+	}
+
+	p("## Summary\n\n")
+	p("| op | count | errors | ops/s | error rate | min | mean | p50 | p90 | p99 | p99.9 | max |\n")
+	p("|---|---:|---:|---:|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+	for _, s := range append(r.Operations, r.Total) {
+		l := s.Latency
+		p("| %s | %d | %d | %.1f | %.4f%% | %.3f | %.3f | %.3f | %.3f | %.3f | %.3f | %.3f |\n",
+			s.Op, s.Count, s.Errors, s.Throughput, 100*s.ErrorRate,
+			l.Min, l.Mean, l.P50, l.P90, l.P99, l.P999, l.Max)
+	}
+	p("\nLatencies are in milliseconds.\n\n")
+	for _, s := range r.Operations {
+		if l := s.KeyLatency; l != nil {
+			// Scans count the rows they returned, multi-gets the keys they asked for.
+			unit := "key"
+			if s.Op == "scan" || s.Op == "query" {
+				unit = "row"
+			}
+			p("%s: %d %ss, %.1f %ss/s; per %s: mean %.3f, p50 %.3f, p99 %.3f, p99.9 %.3f ms.\n\n",
+				s.Op, s.Keys, unit, s.KeysPerSec, unit, unit, l.Mean, l.P50, l.P99, l.P999)
+		}
+	}
+
+	if len(r.ErrorReasons) > 0 {
+		p("## Errors\n\n")
+		p("| op | reason | count |\n")
+		p("|---|---|---:|\n")
+		for _, e := range r.ErrorReasons {
+			p("| %s | %s | %d |\n", e.Op, e.Reason, e.Count)
+		}
+		p("\nMost frequent messages:\n\n")
+		p("| op | reason | count | message |\n")
+		p("|---|---|---:|---|\n")
+		for _, e := range r.TopErrors {
+			p("| %s | %s | %d | %s |\n", e.Op, e.Reason, e.Count, strings.ReplaceAll(e.Message, "|", "\\|"))
+		}
+		p("\n")
+	}
+
+	if v := r.Validation; v != nil {
+		p("## Read validation\n\n")
+		p("%d reads (%g%% of all) checked against the payload generator, %d failed (%.4f%%).\n\n",
+			v.Checked, 100*r.Config.Read.Validate, v.Failed, 100*v.FailureRate)
+		if len(v.Examples) > 0 {
+			p("| id | at | error |\n")
+			p("|---:|---|---|\n")
+			for _, e := range v.Examples {
+				p("| %d | %s | %s |\n", e.ID, e.At.Format(time.RFC3339Nano), e.Error)
+			}
+			p("\n")
+		}
+	}
+
+	if len(r.Stages) > 0 {
+		p("## Stages\n\n")
+		p("| stage | workers | target ops/s | duration | ops/s | error rate | p50 | p99 | efficiency |\n")
+		p("|---:|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+		for _, s := range r.Stages {
+			t := s.Total
+			knee := ""
+			if r.Knee == s.Stage {
+				knee = " **knee**"
+			}
+			p("| %d%s | %d | %.0f | %.1fs | %.1f | %.4f%% | %.3f | %.3f | %.2f |\n",
+				s.Stage, knee, s.Workers, s.TargetOpsPerSec, s.DurationSec, t.Throughput, 100*t.ErrorRate,
+				t.Latency.P50, t.Latency.P99, s.Efficiency)
+		}
+		if r.Knee > 0 {
+			p("\nSaturation: throughput stopped scaling with load after stage %d (%.1f ops/s).\n\n",
+				r.Knee, r.Stages[r.Knee-1].Total.Throughput)
+		} else {
+			p("\nNo saturation point found: throughput kept scaling with load.\n\n")
+		}
+	}
+
+	p("## Config\n\n```json\n")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r.Config); err != nil {
+		return err
+	}
+	p("```\n\n")
+
+	p("## Time series\n\n")
+	p("| second | ops | errors | mean ms | max ms |\n")
+	p("|---:|---:|---:|---:|---:|\n")
+	for _, s := range r.TimeSeries {
+		p("| %d | %d | %d | %.3f | %.3f |\n", s.Second, s.Ops, s.Errors, s.MeanMs, s.MaxMs)
+	}
+	return nil
+}
+
+func f(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
@@ -2,38 +2,54 @@ package lib
 
 import (
 	"context"
-	"db-bench/lib/cassandra"
-	"db-bench/lib/conf"
-	"db-bench/lib/etcd"
-	"db-bench/lib/mongo"
-	"db-bench/lib/mysql"
-	"db-bench/lib/postgre"
 	"fmt"
-	"sync"
+	"strings"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+
+	_ "db-bench/lib/cassandra"
+	_ "db-bench/lib/etcd"
+	_ "db-bench/lib/mongo"
+	_ "db-bench/lib/mysql"
+	_ "db-bench/lib/postgre"
+	_ "db-bench/lib/ydb"
 )
 
-type DatabaseTester interface {
-	Seed(ctx context.Context) error
-	RunTest(ctx context.Context, wg *sync.WaitGroup)
-	Close()
+type DatabaseTester = backend.Tester
+
+// ListBackends returns every registered backend sorted by name.
+func ListBackends() []backend.Backend {
+	return backend.List()
+}
+
+// ConsistencyLevels returns the read consistency levels of dbType, the
+// default first, or nil if it offers no choice.
+func ConsistencyLevels(dbType string) []string {
+	b, ok := backend.Lookup(dbType)
+	if !ok {
+		return nil
+	}
+	return b.Levels()
 }
 
 func GetTester(dbType string, cfg *conf.Config) (DatabaseTester, error) {
+	b, ok := backend.Lookup(dbType)
+	if !ok {
+		return nil, fmt.Errorf("unknown database type: %s (available: %s)", dbType, strings.Join(backend.Names(), ", "))
+	}
+	if cfg.URI == "" {
+		return nil, fmt.Errorf("%s.uri is not set", dbType)
+	}
+	if err := b.CheckSettings(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Workload.Query > 0 && !b.Capabilities.Has(backend.CapJSONQuery) {
+		return nil, fmt.Errorf("%s cannot query targeting_rules by attribute (workload.query)", dbType)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
 	defer cancel()
 
-	switch dbType {
-	case "postgres":
-		return postgre.NewPostgresTester(ctx, cfg)
-	case "cassandra":
-		return cassandra.NewCassandraTester(ctx, cfg)
-	case "mongo":
-		return mongo.NewMongoTester(ctx, cfg)
-	case "etcd":
-		return etcd.NewEtcdTester(ctx, cfg)
-	case "mysql":
-		return mysql.NewMySQLTester(ctx, cfg)
-	default:
-		return nil, fmt.Errorf("unknown database type: %s", dbType)
-	}
+	return b.New(ctx, cfg)
 }
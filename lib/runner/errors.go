@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ErrorCount is how often one error was seen during the measurement.
+type ErrorCount struct {
+	Op      string
+	Reason  string
+	Message string
+	Count   int64
+}
+
+// Limits on the error messages a worker keeps; once maxErrorMessages
+// distinct ones are seen, new ones are only counted by reason.
+const (
+	maxErrorMessages = 100
+	maxMessageLen    = 200
+	otherMessages    = "(other messages)"
+)
+
+type errorKey struct{ op, reason, message string }
+
+type errorLog map[errorKey]int64
+
+func (l errorLog) add(op, reason string, err error) {
+	msg := err.Error()
+	if len(msg) > maxMessageLen {
+		msg = msg[:maxMessageLen] + "..."
+	}
+	k := errorKey{op, reason, msg}
+	if _, ok := l[k]; !ok && len(l) >= maxErrorMessages {
+		k.message = otherMessages
+	}
+	l[k]++
+}
+
+// mergeErrors adds up the workers' errors, most frequent first.
+func mergeErrors(workers []*worker) []ErrorCount {
+	all := errorLog{}
+	for _, w := range workers {
+		for k, n := range w.errors {
+			all[k] += n
+		}
+	}
+	var counts []ErrorCount
+	for k, n := range all {
+		counts = append(counts, ErrorCount{Op: k.op, Reason: k.reason, Message: k.message, Count: n})
+	}
+	slices.SortFunc(counts, func(a, b ErrorCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Op, b.Op), cmp.Compare(a.Message, b.Message))
+	})
+	return counts
+}
@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"db-bench/lib/conf"
+	"db-bench/lib/workload"
+)
+
+// Trial is one fixed-rate run of a FindMax search.
+type Trial struct {
+	TargetOpsPerSec float64
+	// Config is the config the trial ran with.
+	Config *conf.Config
+	Result *Result
+	Passed bool
+	// Reason explains why a trial missed the SLO.
+	Reason string
+}
+
+// Search is the outcome of FindMax.
+type Search struct {
+	Trials []Trial
+	// Best is the index of the fastest trial that met the SLO, or -1.
+	Best int
+	// Aborted is set when ctx was cancelled before the search converged;
+	// the interrupted trial is not part of Trials.
+	Aborted bool
+}
+
+// FindMax looks for the highest target rate op sustains within cfg.FindMax.SLO.
+// It runs short open-loop trials, doubling the rate from MinOpsPerSec until a
+// trial fails or MaxOpsPerSec is reached, then bisects between the fastest
+// passing and the slowest failing rate until they are within Precision.
+func FindMax(ctx context.Context, cfg *conf.Config, op workload.Operator) *Search {
+	fm := cfg.FindMax
+	return search(fm, func(n int, rate float64) (Trial, bool) {
+		c := *cfg
+		c.Profile = conf.Profile{}
+		c.TestDuration = fm.TrialDuration
+		c.Rate = conf.Rate{TargetOpsPerSec: rate}
+		c.Phases.Cooldown = 0
+		if n > 0 {
+			c.Phases.Warmup = fm.Settle
+		}
+		res := Run(ctx, &c, op)
+		if res.Aborted {
+			return Trial{}, false
+		}
+		t := Trial{TargetOpsPerSec: rate, Config: &c, Result: res}
+		t.Passed, t.Reason = meetsSLO(fm.SLO, rate, res)
+		return t, true
+	})
+}
+
+// search drives the FindMax search over trial, which runs trial number n
+// (from 0) at rate and returns false if it was interrupted.
+func search(fm conf.FindMax, trial func(n int, rate float64) (Trial, bool)) *Search {
+	s := &Search{Best: -1}
+	var pass, fail float64
+	rate := fm.MinOpsPerSec
+	for len(s.Trials) < fm.MaxTrials {
+		log.Printf("Trial %d: %.0f ops/s for %v", len(s.Trials)+1, rate, fm.TrialDuration)
+		t, ok := trial(len(s.Trials), rate)
+		if !ok {
+			s.Aborted = true
+			return s
+		}
+		s.Trials = append(s.Trials, t)
+		if t.Passed {
+			log.Printf("Trial %d: %.0f ops/s met the SLO", len(s.Trials), rate)
+			pass = rate
+			s.Best = len(s.Trials) - 1
+		} else {
+			log.Printf("Trial %d: %.0f ops/s missed the SLO: %s", len(s.Trials), rate, t.Reason)
+			fail = rate
+		}
+
+		switch {
+		case fail == 0 && rate >= fm.MaxOpsPerSec:
+			log.Printf("Reached findMax.maxOpsPerSec without missing the SLO")
+			return s
+		case fail == 0:
+			rate = min(2*rate, fm.MaxOpsPerSec)
+		case pass == 0:
+			log.Printf("Even findMax.minOpsPerSec misses the SLO")
+			return s
+		case (fail-pass)/pass <= fm.Precision:
+			return s
+		default:
+			rate = (pass + fail) / 2
+		}
+	}
+	return s
+}
+
+// meetsSLO checks the measurement of a trial at rate against slo.
+func meetsSLO(slo conf.SLO, rate float64, res *Result) (bool, string) {
+	total := res.Stats.Total()
+	if total.Count == 0 {
+		return false, "no operations completed"
+	}
+	elapsed := res.End.Sub(res.Start).Seconds()
+	throughput := float64(total.Count-total.Errors) / elapsed
+	errorRate := float64(total.Errors) / float64(total.Count)
+	p99 := min(max(total.Latency.Quantile(0.99), total.Min), total.Max)
+	switch {
+	case errorRate > slo.ErrorRate:
+		return false, fmt.Sprintf("error rate %.4f%% > %.4f%%", 100*errorRate, 100*slo.ErrorRate)
+	case p99 > slo.P99:
+		return false, fmt.Sprintf("p99 %v > %v", p99, slo.P99)
+	case throughput < slo.MinThroughputRatio*rate:
+		return false, fmt.Sprintf("throughput %.1f ops/s < %.0f%% of target", throughput, 100*slo.MinThroughputRatio)
+	}
+	return true, ""
+}
@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"testing"
+
+	"db-bench/lib/conf"
+)
+
+var testFindMax = conf.FindMax{MinOpsPerSec: 100, MaxOpsPerSec: 100000, Precision: 0.05, MaxTrials: 20}
+
+// capacity is a fake database that meets the SLO up to its rate; stop
+// interrupts the search before trial number stop (never if 0).
+func capacity(rate float64, stop int) func(int, float64) (Trial, bool) {
+	return func(n int, r float64) (Trial, bool) {
+		if stop > 0 && n == stop {
+			return Trial{}, false
+		}
+		return Trial{TargetOpsPerSec: r, Passed: r <= rate}, true
+	}
+}
+
+func TestSearch(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		fm       conf.FindMax
+		capacity float64
+		stop     int
+		// best is the rate of the best trial, 0 for none; trials the
+		// number of trials, 0 to leave it unchecked
+		best    float64
+		trials  int
+		aborted bool
+	}{
+		{"converges", testFindMax, 3000, 0, 0, 0, false},
+		{"on a doubling step", testFindMax, 1600, 0, 0, 0, false},
+		{"stops at maxOpsPerSec", testFindMax, 1e9, 0, 100000, 11, false},
+		{"min misses the SLO", testFindMax, 50, 0, 0, 1, false},
+		{"maxTrials", conf.FindMax{MinOpsPerSec: 100, MaxOpsPerSec: 100000, Precision: 0.05, MaxTrials: 3},
+			3000, 0, 400, 3, false},
+		{"interrupted", testFindMax, 3000, 4, 800, 4, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			s := search(c.fm, capacity(c.capacity, c.stop))
+			if s.Aborted != c.aborted {
+				t.Errorf("aborted = %v, want %v", s.Aborted, c.aborted)
+			}
+			if c.trials > 0 && len(s.Trials) != c.trials {
+				t.Errorf("%d trials, want %d", len(s.Trials), c.trials)
+			}
+			if len(s.Trials) > c.fm.MaxTrials {
+				t.Errorf("%d trials, more than maxTrials %d", len(s.Trials), c.fm.MaxTrials)
+			}
+			var fail float64
+			for _, tr := range s.Trials {
+				if tr.TargetOpsPerSec < c.fm.MinOpsPerSec || tr.TargetOpsPerSec > c.fm.MaxOpsPerSec {
+					t.Errorf("trial at %.0f ops/s outside [%.0f, %.0f]", tr.TargetOpsPerSec, c.fm.MinOpsPerSec, c.fm.MaxOpsPerSec)
+				}
+				if !tr.Passed && (fail == 0 || tr.TargetOpsPerSec < fail) {
+					fail = tr.TargetOpsPerSec
+				}
+			}
+
+			best := 0.0
+			if s.Best >= 0 {
+				best = s.Trials[s.Best].TargetOpsPerSec
+				for _, tr := range s.Trials {
+					if tr.Passed && tr.TargetOpsPerSec > best {
+						t.Errorf("best is %.0f ops/s, but %.0f ops/s passed too", best, tr.TargetOpsPerSec)
+					}
+				}
+			}
+			if c.best > 0 || c.trials > 0 {
+				if best != c.best {
+					t.Errorf("best rate %.0f, want %.0f", best, c.best)
+				}
+				return
+			}
+			// A converged search brackets the capacity within the precision.
+			if best == 0 || best > c.capacity || fail <= c.capacity {
+				t.Fatalf("best %.0f and lowest failing %.0f ops/s do not bracket %.0f", best, fail, c.capacity)
+			}
+			if (fail-best)/best > c.fm.Precision {
+				t.Errorf("stopped with best %.0f and lowest failing %.0f ops/s, beyond precision %g",
+					best, fail, c.fm.Precision)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package runner
+
+import "time"
+
+const (
+	PhaseWarmup   = "warmup"
+	PhaseMeasure  = "measure"
+	PhaseCooldown = "cooldown"
+)
+
+// phases splits a run into warm-up, measurement and cool-down windows.
+// Only operations due inside the measurement window make it into the report.
+type phases struct {
+	measureStart time.Time
+	measureEnd   time.Time
+	end          time.Time
+}
+
+func newPhases(start time.Time, warmup, measure, cooldown time.Duration) phases {
+	p := phases{measureStart: start.Add(warmup)}
+	p.measureEnd = p.measureStart.Add(measure)
+	p.end = p.measureEnd.Add(cooldown)
+	return p
+}
+
+func (p phases) at(t time.Time) string {
+	switch {
+	case t.Before(p.measureStart):
+		return PhaseWarmup
+	case t.Before(p.measureEnd):
+		return PhaseMeasure
+	default:
+		return PhaseCooldown
+	}
+}
@@ -0,0 +1,478 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"db-bench/lib/conf"
+	"db-bench/lib/keygen"
+	"db-bench/lib/stats"
+	"db-bench/lib/workload"
+)
+
+// Result is the outcome of the measurement phase of a run with the
+// statistics of all workers merged.
+type Result struct {
+	Start time.Time
+	End   time.Time
+	Stats *stats.Recorder
+	// Stages is set when the run follows a load profile.
+	Stages []StageResult
+	// Validation is set when reads are checked (cfg.Read.Validate).
+	Validation *Validation
+	// Errors lists the failed operations by message, most frequent first.
+	// Operations cut off by the end of the run are not counted.
+	Errors []ErrorCount
+	// Aborted is set when ctx was cancelled before the run finished.
+	Aborted bool
+}
+
+// Run drives cfg.WorkerCount workers against op through the warm-up,
+// measurement (cfg.TestDuration) and cool-down phases, or until ctx is done,
+// and blocks until every worker has returned. With a load profile the
+// number of workers and the target rate follow its stages instead.
+func Run(ctx context.Context, cfg *conf.Config, op workload.Operator) *Result {
+	keys := keygen.NewFactory(cfg.KeyDistribution, int64(cfg.RecordCount))
+	if cfg.Workload.Insert > 0 {
+		skipStoredIDs(ctx, op, keys)
+	}
+	mix := workload.NewMix(cfg.Workload)
+	rows := workload.NewGenerator(cfg.Payload)
+	stages := stagesOf(cfg)
+	if len(cfg.Profile.Stages) > 0 {
+		log.Printf("RunTest db %s: %d stages, %s", cfg.DB, len(stages), mix)
+	} else {
+		log.Printf("RunTest db %s: %d workers, %s, %s", cfg.DB, cfg.WorkerCount, mix, cfg.Rate)
+	}
+	classifier, _ := op.(workload.Classifier)
+	batch, _ := op.(workload.BatchReader)
+	if cfg.Workload.MultiGet > 0 && cfg.Read.MultiGet.Mode == conf.MultiGetNative && batch == nil {
+		log.Printf("Warning: %s has no native multi-get, reading keys in parallel", cfg.DB)
+	}
+	if cfg.Read.MultiGet.Mode == conf.MultiGetParallel {
+		batch = nil
+	}
+	scanner, _ := op.(workload.Scanner)
+	querier, _ := op.(workload.Querier)
+	if cfg.Workload.Query > 0 {
+		if _, err := rows.Attribute(1, cfg.Query.Path()); err != nil {
+			log.Printf("Warning: query.attribute %s: %v", cfg.Query.Attribute, err)
+		}
+	}
+	var get getter
+	if cfg.Read.Validate > 0 {
+		if get, _ = op.(getter); get != nil {
+			log.Printf("Checking %g%% of reads against the payload generator", 100*cfg.Read.Validate)
+		} else {
+			log.Printf("Warning: %s cannot fetch whole rows, read validation is disabled", cfg.DB)
+		}
+	}
+
+	start := time.Now()
+	ph := newPhases(start, cfg.Phases.Warmup, cfg.TestDuration, cfg.Phases.Cooldown)
+	parent := ctx
+	ctx, cancel := context.WithDeadline(ctx, ph.end)
+	defer cancel()
+
+	// Operations run on their own context so that the ones in flight when the
+	// run stops can finish; they are only cancelled after DrainTimeout.
+	opCtx, cancelOps := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelOps()
+	stopDrain := context.AfterFunc(ctx, func() {
+		time.AfterFunc(cfg.Phases.DrainTimeout, cancelOps)
+	})
+	defer stopDrain()
+	if cfg.Phases.Warmup > 0 {
+		log.Printf("Warming up for %v", cfg.Phases.Warmup)
+		t := time.AfterFunc(cfg.Phases.Warmup, func() { log.Printf("Warm-up done, measuring for %v", cfg.TestDuration) })
+		defer t.Stop()
+	}
+	if cfg.Phases.Cooldown > 0 {
+		t := time.AfterFunc(ph.measureEnd.Sub(start), func() { log.Printf("Measurement done, cooling down for %v", cfg.Phases.Cooldown) })
+		defer t.Stop()
+	}
+
+	histOpts := stats.HistogramOpts{
+		SignificantFigures: cfg.Latency.SignificantFigures,
+		Max:                cfg.Latency.MaxTrackable,
+	}
+	// Stage histograms only feed the per-stage summary, so keep them small.
+	stageOpts := histOpts
+	stageOpts.SignificantFigures = min(stageOpts.SignificantFigures, 2)
+
+	pc := newPacer(cfg.Rate.PerWorker)
+	stageStarts := make([]time.Time, len(stages))
+	maxWorkers := 0
+	at := ph.measureStart
+	for i, st := range stages {
+		stageStarts[i] = at
+		at = at.Add(st.Duration)
+		maxWorkers = max(maxWorkers, st.Workers)
+		if i == 0 {
+			pc.set(0, st, start)
+			cfg.ActiveWorkers.WithLabelValues(cfg.DB).Set(float64(st.Workers))
+			continue
+		}
+		t := time.AfterFunc(stageStarts[i].Sub(start), func() {
+			log.Printf("Stage %d/%d: %d workers, %s for %v", i+1, len(stages), st.Workers,
+				conf.Rate{TargetOpsPerSec: st.TargetOpsPerSec, PerWorker: cfg.Rate.PerWorker}, st.Duration)
+			pc.set(i, st, stageStarts[i])
+			cfg.ActiveWorkers.WithLabelValues(cfg.DB).Set(float64(st.Workers))
+		})
+		defer t.Stop()
+	}
+
+	var wg sync.WaitGroup
+	workers := make([]*worker, maxWorkers)
+	for i := range workers {
+		w := &worker{
+			id:         i,
+			cfg:        cfg,
+			op:         op,
+			classifier: classifier,
+			batch:      batch,
+			scanner:    scanner,
+			querier:    querier,
+			mix:        mix,
+			keys:       keys,
+			gen:        keys.New(i),
+			rows:       rows,
+			get:        get,
+			errors:     errorLog{},
+			rnd:        rand.New(rand.NewSource(time.Now().UnixNano() + int64(i))),
+			pacer:      pc,
+			phases:     ph,
+			rec:        stats.NewRecorder(ph.measureStart, histOpts),
+		}
+		if len(cfg.Profile.Stages) > 0 {
+			w.stageStarts = stageStarts
+			for _, t := range stageStarts {
+				w.stageRecs = append(w.stageRecs, stats.NewRecorder(t, stageOpts))
+			}
+		}
+		workers[i] = w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx, opCtx)
+		}()
+	}
+	wg.Wait()
+	cfg.ActiveWorkers.WithLabelValues(cfg.DB).Set(0)
+
+	end := time.Now()
+	if end.After(ph.measureEnd) {
+		end = ph.measureEnd
+	}
+	if end.Before(ph.measureStart) {
+		end = ph.measureStart
+	}
+	res := &Result{
+		Start:   ph.measureStart,
+		End:     end,
+		Stats:   stats.NewRecorder(ph.measureStart, histOpts),
+		Aborted: parent.Err() != nil,
+	}
+	for _, w := range workers {
+		res.Stats.Merge(w.rec)
+	}
+	if get != nil {
+		res.Validation = mergeValidation(workers, cfg.Read.ValidationExamples)
+	}
+	res.Errors = mergeErrors(workers)
+	if len(cfg.Profile.Stages) > 0 {
+		for i, st := range stages {
+			if !stageStarts[i].Before(end) {
+				break
+			}
+			sr := StageResult{
+				Stage: st,
+				Start: stageStarts[i],
+				End:   stageStarts[i].Add(st.Duration),
+				Stats: stats.NewRecorder(stageStarts[i], stageOpts),
+			}
+			if sr.End.After(end) {
+				sr.End = end
+			}
+			for _, w := range workers {
+				sr.Stats.Merge(w.stageRecs[i])
+			}
+			res.Stages = append(res.Stages, sr)
+		}
+	}
+	return res
+}
+
+// maxIDer reports the highest stored id, like seed.Inspector.
+type maxIDer interface {
+	MaxID(ctx context.Context) (int64, error)
+}
+
+// skipStoredIDs starts inserts past the highest id in the table, so that a
+// run against a table earlier runs inserted into does not collide with them.
+func skipStoredIDs(ctx context.Context, op workload.Operator, keys *keygen.Factory) {
+	m, ok := op.(maxIDer)
+	if !ok {
+		return
+	}
+	maxID, err := m.MaxID(ctx)
+	if err != nil {
+		log.Printf("Warning: cannot read the highest stored id, inserts may collide: %v", err)
+		return
+	}
+	keys.InsertAfter(maxID)
+}
+
+type worker struct {
+	id   int
+	cfg  *conf.Config
+	op   workload.Operator
+	mix  *workload.Mix
+	keys *keygen.Factory
+	gen  keygen.KeyGenerator
+	rows *workload.Generator
+	// nil unless reads are validated
+	get getter
+	rnd *rand.Rand
+	// nil means closed loop: issue the next operation as soon as the previous one returns
+	sched  *schedule
+	pacer  *pacer
+	phases phases
+	rec    *stats.Recorder
+	// per-stage recorders, set only for runs with a load profile
+	stageStarts []time.Time
+	stageRecs   []*stats.Recorder
+	validation  Validation
+	// nil if op does not know its driver's errors
+	classifier workload.Classifier
+	errors     errorLog
+	// nil when multi-gets are served by parallel reads
+	batch   workload.BatchReader
+	scanner workload.Scanner
+	querier workload.Querier
+}
+
+// loop issues operations until ctx is done; each operation runs on opCtx.
+// Workers beyond the current stage's worker count stay parked.
+func (w *worker) loop(ctx, opCtx context.Context) {
+	var p *pace
+	for ctx.Err() == nil {
+		if cur := w.pacer.current(); cur != p {
+			p = cur
+			w.sched = p.scheduleFor(w.id, w.cfg.Rate.PerWorker)
+		}
+		if w.id >= p.workers {
+			select {
+			case <-ctx.Done():
+			case <-p.changed:
+			}
+			continue
+		}
+
+		var start time.Time
+		if w.sched != nil {
+			var ok bool
+			if start, ok = w.sched.wait(ctx, p.changed); !ok {
+				continue
+			}
+		}
+		req := w.prepare(w.mix.Pick(w.rnd))
+		if w.sched == nil {
+			// Closed loop: start the clock once the row is built.
+			start = time.Now()
+		}
+		kind := req.kind
+		var err, invalid error
+		if req.validate {
+			err, invalid = w.readAndCheck(opCtx, req.id)
+		} else {
+			err = w.do(opCtx, &req)
+		}
+		latency := time.Since(start)
+		var reason string
+		if err != nil {
+			reason = workload.Classify(w.classifier, err)
+			if reason == workload.ReasonCanceled && opCtx.Err() != nil {
+				// Cut off by the end of the run, not failed by the database.
+				continue
+			}
+		}
+		phase := w.phases.at(start)
+		if req.validate {
+			w.recordCheck(req.id, start, phase, invalid)
+		}
+		if phase == PhaseMeasure {
+			w.record(w.rec, req, start, latency, err)
+			if rec := w.stageRecorder(start); rec != nil {
+				w.record(rec, req, start, latency, err)
+			}
+			if err != nil {
+				w.errors.add(string(kind), reason, err)
+			}
+		}
+		w.cfg.ReadLatency.WithLabelValues(w.cfg.DB, string(kind), phase, w.cfg.Consistency).Observe(latency.Seconds())
+		if err != nil {
+			w.cfg.ReadErrorsTotal.WithLabelValues(w.cfg.DB, string(kind), phase, reason, w.cfg.Consistency).Inc()
+		} else {
+			w.cfg.ReadsTotal.WithLabelValues(w.cfg.DB, string(kind), phase, w.cfg.Consistency).Inc()
+		}
+	}
+}
+
+func (w *worker) record(rec *stats.Recorder, req request, start time.Time, latency time.Duration, err error) {
+	switch req.kind {
+	case workload.OpMultiGet:
+		rec.RecordBatch(string(req.kind), start, latency, len(req.ids), err)
+		return
+	case workload.OpScan, workload.OpQuery:
+		rec.RecordBatch(string(req.kind), start, latency, req.rows, err)
+		return
+	}
+	rec.Record(string(req.kind), start, latency, err)
+}
+
+// stageRecorder returns the recorder of the stage an operation due at t
+// belongs to.
+func (w *worker) stageRecorder(t time.Time) *stats.Recorder {
+	for i := len(w.stageStarts) - 1; i >= 0; i-- {
+		if !t.Before(w.stageStarts[i]) {
+			return w.stageRecs[i]
+		}
+	}
+	return nil
+}
+
+// request is one operation with its arguments.
+type request struct {
+	kind workload.Op
+	id   int64
+	rule conf.ExperimentRule
+	// ids of a multi-get
+	ids []int64
+	// scan is the first page of a scan; do sets rows to the rows a scan
+	// or a query read.
+	scan workload.ScanQuery
+	rows int
+	// value of the queried attribute, nil if the row has none
+	value any
+	// validate reads the whole row and checks it against the generator.
+	validate bool
+}
+
+// prepare picks the key and builds the row for an operation of kind.
+func (w *worker) prepare(kind workload.Op) request {
+	req := request{kind: kind}
+	switch kind {
+	case workload.OpInsert:
+		req.id = w.keys.NextInsertID()
+		req.rule = w.rows.Rule(req.id)
+	case workload.OpUpdate, workload.OpReadModifyWrite:
+		req.id = w.gen.Next()
+		req.rule = w.rows.UpdatedRule(req.id, w.rnd.Int31())
+	case workload.OpRead:
+		req.id = w.gen.Next()
+		req.validate = w.get != nil && w.rnd.Float64() < w.cfg.Read.Validate
+	case workload.OpMultiGet:
+		req.ids = w.distinctKeys(w.cfg.Read.MultiGet.BatchSize)
+	case workload.OpScan:
+		req.id = w.gen.Next()
+		req.scan = w.scanQuery(req.id)
+	case workload.OpQuery:
+		req.id = w.gen.Next()
+		req.value, _ = w.rows.Attribute(req.id, w.cfg.Query.Path())
+	default:
+		req.id = w.gen.Next()
+	}
+	return req
+}
+
+// distinctKeys draws up to n different keys; with a skewed distribution a
+// batch may come out smaller rather than loop on the hot keys.
+func (w *worker) distinctKeys(n int) []int64 {
+	ids := make([]int64, 0, n)
+	seen := make(map[int64]bool, n)
+	for range 4 * n {
+		id := w.gen.Next()
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+			if len(ids) == n {
+				break
+			}
+		}
+	}
+	return ids
+}
+
+var (
+	errScanUnsupported  = errors.New("scans are not supported")
+	errQueryUnsupported = errors.New("queries by attribute are not supported")
+	errNoAttribute      = errors.New("row has no value at query.attribute")
+)
+
+// scanQuery builds the first page of a scan that starts at id.
+func (w *worker) scanQuery(id int64) workload.ScanQuery {
+	sc := w.cfg.Scan
+	q := workload.ScanQuery{Mode: sc.Mode, Limit: sc.MaxLength}
+	if sc.LengthDist == "uniform" {
+		q.Limit = 1 + w.rnd.Intn(sc.MaxLength)
+	}
+	switch sc.Mode {
+	case conf.ScanRange:
+		q.From = id
+	case conf.ScanKeyset:
+		q.From = id - 1
+	case conf.ScanOffset:
+		// The page id falls on, as a paginated listing would show it.
+		q.Offset = int(id-1) / q.Limit * q.Limit
+	}
+	return q
+}
+
+func (w *worker) do(ctx context.Context, req *request) error {
+	switch req.kind {
+	case workload.OpScan:
+		var err error
+		if w.scanner == nil {
+			return errScanUnsupported
+		}
+		req.rows, err = workload.ScanPages(ctx, w.scanner, req.scan, w.cfg.Scan.Pages)
+		return err
+	case workload.OpQuery:
+		var err error
+		switch {
+		case w.querier == nil:
+			return errQueryUnsupported
+		case req.value == nil:
+			return errNoAttribute
+		}
+		req.rows, err = w.querier.Query(ctx, req.value)
+		return err
+	case workload.OpMultiGet:
+		if w.batch == nil {
+			return workload.ReadParallel(ctx, req.ids, w.op.Read)
+		}
+		return w.batch.ReadMany(ctx, req.ids)
+	case workload.OpUpdate:
+		return w.op.Update(ctx, req.rule)
+	case workload.OpInsert:
+		if err := w.op.Insert(ctx, req.rule); err != nil {
+			return err
+		}
+		w.keys.Advance(req.id)
+		return nil
+	case workload.OpDelete:
+		return w.op.Delete(ctx, req.id)
+	case workload.OpReadModifyWrite:
+		if err := w.op.Read(ctx, req.id); err != nil {
+			return err
+		}
+		return w.op.Update(ctx, req.rule)
+	default:
+		return w.op.Read(ctx, req.id)
+	}
+}
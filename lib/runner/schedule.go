@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// schedule hands out intended start times spaced evenly at a fixed rate.
+// Latency is measured from the intended time rather than from when the worker
+// got around to issuing the operation, so time spent queued behind a slow
+// database is counted (coordinated-omission correction).
+type schedule struct {
+	start    time.Time
+	interval float64 // nanoseconds between operations
+	slot     atomic.Int64
+}
+
+func newSchedule(start time.Time, opsPerSec float64) *schedule {
+	return &schedule{start: start, interval: float64(time.Second) / opsPerSec}
+}
+
+func (s *schedule) next() time.Time {
+	k := s.slot.Add(1) - 1
+	return s.start.Add(time.Duration(float64(k) * s.interval))
+}
+
+// wait blocks until the next slot is due and returns its intended start time.
+// It returns false if ctx is done or abandon is closed first.
+func (s *schedule) wait(ctx context.Context, abandon <-chan struct{}) (time.Time, bool) {
+	intended := s.next()
+	d := time.Until(intended)
+	if d <= 0 {
+		return intended, ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return intended, false
+	case <-abandon:
+		return intended, false
+	case <-timer.C:
+		return intended, true
+	}
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"sync/atomic"
+	"time"
+
+	"db-bench/lib/conf"
+	"db-bench/lib/stats"
+)
+
+// StageResult holds the statistics of one stage of a load profile.
+type StageResult struct {
+	conf.Stage
+	Start time.Time
+	End   time.Time
+	Stats *stats.Recorder
+}
+
+// stagesOf returns the load profile of cfg; a run without one is a single
+// stage covering the whole measurement.
+func stagesOf(cfg *conf.Config) []conf.Stage {
+	if len(cfg.Profile.Stages) > 0 {
+		return cfg.Profile.Stages
+	}
+	return []conf.Stage{{Duration: cfg.TestDuration, Workers: cfg.WorkerCount, TargetOpsPerSec: cfg.Rate.TargetOpsPerSec}}
+}
+
+// pace is the load applied by the workers during one stage. It is replaced
+// as a whole at stage boundaries; changed is closed when that happens.
+type pace struct {
+	stage   int
+	start   time.Time
+	workers int
+	rate    float64
+	shared  *schedule
+	changed chan struct{}
+}
+
+type pacer struct {
+	perWorker bool
+	cur       atomic.Pointer[pace]
+}
+
+func newPacer(perWorker bool) *pacer {
+	return &pacer{perWorker: perWorker}
+}
+
+// set switches to stage st starting at start. Warm-up runs at the first
+// stage's load and cool-down at the last one's.
+func (p *pacer) set(i int, st conf.Stage, start time.Time) {
+	next := &pace{stage: i, start: start, workers: st.Workers, rate: st.TargetOpsPerSec, changed: make(chan struct{})}
+	if next.rate > 0 && !p.perWorker {
+		next.shared = newSchedule(start, next.rate)
+	}
+	if prev := p.cur.Swap(next); prev != nil {
+		close(prev.changed)
+	}
+}
+
+func (p *pacer) current() *pace { return p.cur.Load() }
+
+// scheduleFor returns the schedule worker i follows under this pace, or nil
+// for a closed loop.
+func (p *pace) scheduleFor(i int, perWorker bool) *schedule {
+	if p.rate <= 0 || !perWorker {
+		return p.shared
+	}
+	// Stagger per-worker schedules so workers don't fire in lockstep.
+	offset := time.Duration(float64(time.Second) / p.rate * float64(i) / float64(p.workers))
+	return newSchedule(p.start.Add(offset), p.rate)
+}
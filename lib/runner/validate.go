@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"db-bench/lib/conf"
+	"db-bench/lib/workload"
+)
+
+// Validation counts the reads of the measurement phase that were checked
+// against the payload generator.
+type Validation struct {
+	Checked int64
+	Failed  int64
+	// Examples holds the first failures, up to cfg.Read.ValidationExamples.
+	Examples []ValidationFailure
+}
+
+// ValidationFailure is a read that returned a missing or wrong row.
+type ValidationFailure struct {
+	ID    int64
+	At    time.Time
+	Error string
+}
+
+// getter fetches a whole row; every backend.Tester implements it.
+type getter interface {
+	Get(ctx context.Context, id int64) (conf.ExperimentRule, error)
+}
+
+var errMissing = errors.New("row is missing")
+
+// readAndCheck reads row id in full and compares it with the generator. err
+// is the outcome of the read itself; invalid is set when the row is missing
+// although the workload never deletes, or differs from what was written.
+func (w *worker) readAndCheck(ctx context.Context, id int64) (err, invalid error) {
+	got, err := w.get.Get(ctx, id)
+	switch {
+	case errors.Is(err, workload.ErrNotFound):
+		if w.cfg.Workload.Delete == 0 {
+			return err, errMissing
+		}
+		return err, nil
+	case err != nil:
+		return err, nil
+	}
+	return nil, workload.Compare(got, w.rows.Expected(id, got))
+}
+
+// recordCheck accounts a validated read of id due at start.
+func (w *worker) recordCheck(id int64, start time.Time, phase string, invalid error) {
+	if invalid != nil {
+		w.cfg.ReadValidationFailures.WithLabelValues(w.cfg.DB, phase, w.cfg.Consistency).Inc()
+	}
+	if phase != PhaseMeasure {
+		return
+	}
+	w.validation.Checked++
+	if invalid == nil {
+		return
+	}
+	w.validation.Failed++
+	if len(w.validation.Examples) < w.cfg.Read.ValidationExamples {
+		w.validation.Examples = append(w.validation.Examples, ValidationFailure{ID: id, At: start, Error: invalid.Error()})
+	}
+}
+
+// mergeValidation adds up the workers' checks, keeping the earliest limit
+// examples.
+func mergeValidation(workers []*worker, limit int) *Validation {
+	v := &Validation{}
+	for _, w := range workers {
+		v.Checked += w.validation.Checked
+		v.Failed += w.validation.Failed
+		v.Examples = append(v.Examples, w.validation.Examples...)
+	}
+	slices.SortFunc(v.Examples, func(a, b ValidationFailure) int { return a.At.Compare(b.At) })
+	if len(v.Examples) > limit {
+		v.Examples = v.Examples[:limit]
+	}
+	return v
+}
@@ -0,0 +1,195 @@
+// Package seed loads the initial dataset for a benchmark: rows 1..RecordCount
+// written in batches from several goroutines, with progress logging.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"db-bench/lib/conf"
+	"db-bench/lib/workload"
+)
+
+// Inspector reads back what is stored, to resume and verify a seed.
+type Inspector interface {
+	// Count returns the number of rows in the table.
+	Count(ctx context.Context) (int64, error)
+	// MaxID returns the highest id in the table, or 0 if it is empty.
+	MaxID(ctx context.Context) (int64, error)
+	// Get returns the row with id, or workload.ErrNotFound.
+	Get(ctx context.Context, id int64) (conf.ExperimentRule, error)
+}
+
+// InsertFunc writes one batch of rows. It must be safe for concurrent use and
+// idempotent, so that seeding an already seeded table is harmless.
+type InsertFunc func(ctx context.Context, rows []conf.ExperimentRule) error
+
+// maxBackoff caps the pause between retries of a batch.
+const maxBackoff = 10 * time.Second
+
+// Run writes rows 1..cfg.RecordCount with insert in batches of
+// cfg.Seed.BatchSize from cfg.Seed.Workers goroutines, retrying failed batches
+// with exponential backoff. It fails if any batch is still failing after
+// cfg.Seed.Retries retries or if db holds fewer rows than expected afterwards.
+// With cfg.Seed.Resume it starts near the highest id already in db.
+// name prefixes the log lines.
+func Run(ctx context.Context, name string, cfg *conf.Config, db Inspector, insert InsertFunc) error {
+	total := int64(cfg.RecordCount)
+	batchSize := int64(cfg.Seed.BatchSize)
+	from := int64(1)
+	if cfg.Seed.Resume {
+		maxID, err := db.MaxID(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to find where to resume: %w", err)
+		}
+		// Batches complete out of order, so ids just below the highest one
+		// may be missing: rewrite the batches that could have been in flight.
+		from = max(1, maxID+1-int64(cfg.Seed.Workers)*batchSize)
+		log.Printf("%s: Highest present id is %d, resuming from %d", name, maxID, from)
+		if count, err := db.Count(ctx); err == nil && count < maxID {
+			log.Printf("Warning: %s: %d ids up to %d are missing; only a seed without resume refills gaps below %d",
+				name, maxID-count, maxID, from)
+		}
+	}
+	log.Printf("%s: Writing %d rows in batches of %d from %d workers...", name, max(total-from+1, 0), batchSize, cfg.Seed.Workers)
+
+	gen := workload.NewGenerator(cfg.Payload)
+	p := newProgress(name, max(total-from+1, 0))
+	stop := p.report(cfg.Seed.ProgressInterval)
+	defer stop()
+
+	batches := make(chan int64)
+	go func() {
+		defer close(batches)
+		for first := from; first <= total; first += batchSize {
+			select {
+			case batches <- first:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range cfg.Seed.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rows := make([]conf.ExperimentRule, 0, batchSize)
+			for first := range batches {
+				rows = rows[:0]
+				for id := first; id < first+batchSize && id <= total; id++ {
+					rows = append(rows, gen.Rule(id))
+				}
+				if err := insertWithRetry(ctx, name, cfg.Seed, insert, rows); err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					log.Printf("Error: %s batch insert failed for ids %d..%d after %d retries: %v",
+						name, first, rows[len(rows)-1].ID, cfg.Seed.Retries, err)
+					p.failed.Add(int64(len(rows)))
+					continue
+				}
+				p.done.Add(int64(len(rows)))
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.summary()
+	if failed := p.failed.Load(); failed > 0 {
+		return fmt.Errorf("%d rows could not be written, re-run seed to fill the gaps", failed)
+	}
+
+	count, err := db.Count(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count rows: %w", err)
+	}
+	if count < total {
+		return fmt.Errorf("table has %d rows after seeding, want at least %d", count, total)
+	}
+	log.Printf("%s: Table has %d rows", name, count)
+	return nil
+}
+
+func insertWithRetry(ctx context.Context, name string, cfg conf.Seed, insert InsertFunc, rows []conf.ExperimentRule) error {
+	backoff := cfg.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := insert(ctx, rows)
+		if err == nil || attempt > cfg.Retries || ctx.Err() != nil {
+			return err
+		}
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+		log.Printf("Warning: %s batch insert failed for ids %d..%d (attempt %d), retrying in %v: %v",
+			name, rows[0].ID, rows[len(rows)-1].ID, attempt, wait.Round(time.Millisecond), err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+type progress struct {
+	name   string
+	total  int64
+	start  time.Time
+	done   atomic.Int64
+	failed atomic.Int64
+}
+
+func newProgress(name string, total int64) *progress {
+	return &progress{name: name, total: total, start: time.Now()}
+}
+
+// report logs progress every interval until the returned stop is called.
+func (p *progress) report(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	quit := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				p.log()
+			case <-quit:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(quit)
+	}
+}
+
+func (p *progress) log() {
+	done, failed := p.done.Load(), p.failed.Load()
+	elapsed := time.Since(p.start)
+	rate := float64(done+failed) / elapsed.Seconds()
+	eta := "unknown"
+	if rate > 0 {
+		eta = time.Duration(float64(p.total-done-failed) / rate * float64(time.Second)).Round(time.Second).String()
+	}
+	log.Printf("%s: %d/%d rows (%.1f%%), %.0f rows/s, ETA %s", p.name, done, p.total,
+		100*float64(done)/float64(max(p.total, 1)), rate, eta)
+}
+
+func (p *progress) summary() {
+	elapsed := time.Since(p.start)
+	done, failed := p.done.Load(), p.failed.Load()
+	log.Printf("%s: %d rows written in %v (%.0f rows/s)", p.name, done, elapsed.Round(time.Millisecond),
+		float64(done)/elapsed.Seconds())
+	if failed > 0 {
+		log.Printf("Warning: %s: %d rows failed to write", p.name, failed)
+	}
+}
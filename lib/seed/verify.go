@@ -0,0 +1,85 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+
+	"db-bench/lib/conf"
+	"db-bench/lib/workload"
+)
+
+// maxReported limits how many bad rows Verify logs individually.
+const maxReported = 10
+
+// Verify checks that db holds a complete seed: at least cfg.RecordCount rows
+// up to id cfg.RecordCount, and that cfg.Seed.VerifySamples random rows (plus
+// the first and the last one) match what Run writes. Rows changed by a
+// benchmark with updates or deletes show up as mismatches.
+func Verify(ctx context.Context, name string, cfg *conf.Config, db Inspector) error {
+	total := int64(cfg.RecordCount)
+	if total < 1 {
+		return errors.New("recordCount must be positive")
+	}
+	count, err := db.Count(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count rows: %w", err)
+	}
+	maxID, err := db.MaxID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find the highest id: %w", err)
+	}
+	log.Printf("%s: %d rows, highest id %d, expected %d rows", name, count, maxID, total)
+
+	var problems []string
+	if count < total {
+		problems = append(problems, fmt.Sprintf("%d rows missing", total-count))
+	}
+	if maxID < total {
+		problems = append(problems, fmt.Sprintf("highest id is %d, want %d", maxID, total))
+	}
+
+	ids := []int64{1, total}
+	rnd := rand.New(rand.NewSource(rand.Int63()))
+	for range cfg.Seed.VerifySamples {
+		ids = append(ids, 1+rnd.Int63n(total))
+	}
+	gen := workload.NewGenerator(cfg.Payload)
+	var missing, mismatched int
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		got, err := db.Get(ctx, id)
+		switch {
+		case errors.Is(err, workload.ErrNotFound):
+			missing++
+			if missing+mismatched <= maxReported {
+				log.Printf("%s: row %d is missing", name, id)
+			}
+		case err != nil:
+			return fmt.Errorf("failed to read row %d: %w", id, err)
+		default:
+			if err := workload.Compare(got, gen.Rule(id)); err != nil {
+				mismatched++
+				if missing+mismatched <= maxReported {
+					log.Printf("%s: row %d: %v", name, id, err)
+				}
+			}
+		}
+	}
+	log.Printf("%s: sampled %d rows, %d missing, %d mismatched", name, len(ids), missing, mismatched)
+	if missing > 0 {
+		problems = append(problems, fmt.Sprintf("%d of %d sampled rows missing", missing, len(ids)))
+	}
+	if mismatched > 0 {
+		problems = append(problems, fmt.Sprintf("%d of %d sampled rows differ from the generator", mismatched, len(ids)))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("verification failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// HistogramOpts configures the resolution and range of a Histogram.
+type HistogramOpts struct {
+	// SignificantFigures is the number of decimal digits kept for every
+	// recorded value (1..5); 3 means values are accurate to 0.1%.
+	SignificantFigures int
+	// Max is the largest latency tracked precisely; larger values are clamped.
+	Max time.Duration
+}
+
+// Histogram is a high-dynamic-range histogram (the HdrHistogram layout) over
+// microseconds: buckets are powers of two, each split into linear sub-buckets
+// so the relative error is bounded by SignificantFigures. It is not safe for
+// concurrent use; record per worker and Merge afterwards.
+type Histogram struct {
+	opts             HistogramOpts
+	subHalfMagnitude int
+	subHalfCount     int64
+	subMask          int64
+	highest          int64
+	counts           []int64
+	total            int64
+}
+
+func NewHistogram(opts HistogramOpts) *Histogram {
+	if opts.SignificantFigures < 1 || opts.SignificantFigures > 5 {
+		opts.SignificantFigures = 3
+	}
+	if opts.Max <= 0 {
+		opts.Max = time.Minute
+	}
+
+	largestSingleUnit := 2 * int64(math.Pow10(opts.SignificantFigures))
+	subCountMagnitude := int(math.Ceil(math.Log2(float64(largestSingleUnit))))
+	subHalfMagnitude := max(subCountMagnitude, 1) - 1
+	subCount := int64(1) << (subHalfMagnitude + 1)
+
+	highest := max(opts.Max.Microseconds(), 2)
+	bucketCount := 1
+	for smallest := subCount; smallest <= highest; smallest <<= 1 {
+		bucketCount++
+	}
+
+	return &Histogram{
+		opts:             opts,
+		subHalfMagnitude: subHalfMagnitude,
+		subHalfCount:     subCount / 2,
+		subMask:          subCount - 1,
+		highest:          highest,
+		counts:           make([]int64, (bucketCount+1)*int(subCount/2)),
+	}
+}
+
+func (h *Histogram) Opts() HistogramOpts { return h.opts }
+
+func (h *Histogram) bucketIndex(v int64) int {
+	pow2Ceiling := 64 - bits.LeadingZeros64(uint64(v|h.subMask))
+	return pow2Ceiling - (h.subHalfMagnitude + 1)
+}
+
+func (h *Histogram) countsIndex(v int64) int {
+	bucket := h.bucketIndex(v)
+	sub := v >> bucket
+	return (bucket+1)<<h.subHalfMagnitude + int(sub-h.subHalfCount)
+}
+
+// valueAt returns the highest value that maps to counts[i].
+func (h *Histogram) valueAt(i int) int64 {
+	bucket := (i >> h.subHalfMagnitude) - 1
+	sub := int64(i)&(h.subHalfCount-1) + h.subHalfCount
+	if bucket < 0 {
+		sub -= h.subHalfCount
+		bucket = 0
+	}
+	return (sub+1)<<bucket - 1
+}
+
+func (h *Histogram) Record(d time.Duration) {
+	v := min(max(d.Microseconds(), 0), h.highest)
+	h.counts[h.countsIndex(v)]++
+	h.total++
+}
+
+// Merge adds the counts of other, which must use the same options.
+func (h *Histogram) Merge(other *Histogram) {
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+	h.total += other.total
+}
+
+func (h *Histogram) Count() int64 { return h.total }
+
+// Quantile returns the q-th quantile (0..1) with the configured precision.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(h.total)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			return time.Duration(h.valueAt(i)) * time.Microsecond
+		}
+	}
+	return time.Duration(h.highest) * time.Microsecond
+}
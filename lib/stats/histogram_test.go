@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	for _, sf := range []int{1, 2, 3, 4} {
+		h := NewHistogram(HistogramOpts{SignificantFigures: sf, Max: time.Second})
+		precision := math.Pow10(-sf)
+		prev := -1
+		for v := int64(0); v <= h.highest; v += 1 + v/1000 {
+			i := h.countsIndex(v)
+			if i < prev {
+				t.Fatalf("sf %d: value %d maps to bucket %d before bucket %d of a smaller value", sf, v, i, prev)
+			}
+			prev = i
+			top := h.valueAt(i)
+			if top < v {
+				t.Fatalf("sf %d: value %d maps to bucket %d, which ends at %d", sf, v, i, top)
+			}
+			if v > 0 && float64(top-v)/float64(v) > precision {
+				t.Fatalf("sf %d: value %d reported as %d, beyond %g relative error", sf, v, top, precision)
+			}
+		}
+	}
+}
+
+func TestHistogramClamps(t *testing.T) {
+	h := NewHistogram(HistogramOpts{SignificantFigures: 3, Max: time.Second})
+	h.Record(-time.Millisecond)
+	h.Record(time.Hour)
+	if got := h.Quantile(0); got != 0 {
+		t.Errorf("negative latency recorded as %v, want 0", got)
+	}
+	if got := h.Quantile(1); got < time.Second || got > time.Second+time.Millisecond {
+		t.Errorf("latency above Max recorded as %v, want about %v", got, time.Second)
+	}
+	if got := NewHistogram(HistogramOpts{}).Quantile(0.5); got != 0 {
+		t.Errorf("quantile of an empty histogram %v, want 0", got)
+	}
+}
+
+// samples returns n log-uniform latencies between 1µs and 10s.
+func samples(rnd *rand.Rand, n int) []time.Duration {
+	d := make([]time.Duration, n)
+	for i := range d {
+		d[i] = time.Duration(math.Pow(10, 7*rnd.Float64())) * time.Microsecond
+	}
+	return d
+}
+
+// checkQuantiles compares h with the exact quantiles of the sorted values.
+func checkQuantiles(t *testing.T, h *Histogram, sorted []time.Duration, sf int) {
+	t.Helper()
+	precision := math.Pow10(-sf)
+	for _, q := range []float64{0, 0.01, 0.25, 0.5, 0.9, 0.99, 0.999, 1} {
+		rank := max(int(math.Ceil(q*float64(len(sorted)))), 1)
+		want := sorted[rank-1]
+		got := h.Quantile(q)
+		if got < want || float64(got-want) > precision*float64(want)+float64(time.Microsecond) {
+			t.Errorf("sf %d: quantile %g = %v, exact %v", sf, q, got, want)
+		}
+	}
+}
+
+func TestHistogramQuantiles(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, sf := range []int{2, 3, 4} {
+		values := samples(rnd, 100000)
+		h := NewHistogram(HistogramOpts{SignificantFigures: sf, Max: time.Minute})
+		for _, v := range values {
+			h.Record(v)
+		}
+		if h.Count() != int64(len(values)) {
+			t.Fatalf("count %d, want %d", h.Count(), len(values))
+		}
+		slices.Sort(values)
+		checkQuantiles(t, h, values, sf)
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	opts := HistogramOpts{SignificantFigures: 3, Max: time.Minute}
+	all := NewHistogram(opts)
+	merged := NewHistogram(opts)
+	var values []time.Duration
+	for range 4 {
+		part := NewHistogram(opts)
+		for _, v := range samples(rnd, 10000) {
+			part.Record(v)
+			all.Record(v)
+			values = append(values, v)
+		}
+		merged.Merge(part)
+	}
+	if merged.Count() != all.Count() {
+		t.Fatalf("merged count %d, want %d", merged.Count(), all.Count())
+	}
+	if !slices.Equal(merged.counts, all.counts) {
+		t.Fatal("merged buckets differ from recording every value in one histogram")
+	}
+	slices.Sort(values)
+	checkQuantiles(t, merged, values, 3)
+}
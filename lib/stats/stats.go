@@ -0,0 +1,159 @@
+package stats
+
+import (
+	"sort"
+	"time"
+)
+
+// Recorder collects the statistics of a single worker. It is not safe for
+// concurrent use; every worker owns one and they are merged after the run.
+type Recorder struct {
+	start  time.Time
+	opts   HistogramOpts
+	ops    map[string]*OpStats
+	series []Interval
+}
+
+type OpStats struct {
+	Count   int64
+	Errors  int64
+	Sum     time.Duration
+	Min     time.Duration
+	Max     time.Duration
+	Latency *Histogram
+	// Keys and KeyLatency are only set for operations on several keys:
+	// the keys they covered and the latency divided among them.
+	Keys       int64
+	KeyLatency *Histogram
+}
+
+// Interval holds the totals of one second of the run.
+type Interval struct {
+	Ops    int64
+	Errors int64
+	Sum    time.Duration
+	Max    time.Duration
+}
+
+func NewRecorder(start time.Time, opts HistogramOpts) *Recorder {
+	return &Recorder{start: start, opts: opts, ops: map[string]*OpStats{}}
+}
+
+func (r *Recorder) op(name string, minLatency time.Duration) *OpStats {
+	s := r.ops[name]
+	if s == nil {
+		s = &OpStats{Min: minLatency, Latency: NewHistogram(r.opts)}
+		r.ops[name] = s
+	}
+	return s
+}
+
+// Record adds one operation that was due at intended and took latency.
+func (r *Recorder) Record(op string, intended time.Time, latency time.Duration, err error) {
+	s := r.op(op, latency)
+	s.Count++
+	s.Sum += latency
+	s.Min = min(s.Min, latency)
+	s.Max = max(s.Max, latency)
+	s.Latency.Record(latency)
+
+	sec := int(intended.Sub(r.start) / time.Second)
+	if sec < 0 {
+		sec = 0
+	}
+	for len(r.series) <= sec {
+		r.series = append(r.series, Interval{})
+	}
+	iv := &r.series[sec]
+	iv.Ops++
+	iv.Sum += latency
+	iv.Max = max(iv.Max, latency)
+
+	if err != nil {
+		s.Errors++
+		iv.Errors++
+	}
+}
+
+// RecordBatch adds one operation on keys keys, see Record.
+func (r *Recorder) RecordBatch(op string, intended time.Time, latency time.Duration, keys int, err error) {
+	r.Record(op, intended, latency, err)
+	s := r.ops[op]
+	if s.KeyLatency == nil {
+		s.KeyLatency = NewHistogram(r.opts)
+	}
+	s.Keys += int64(keys)
+	s.KeyLatency.Record(latency / time.Duration(max(keys, 1)))
+}
+
+// Merge folds other into r. Both must share the same start time and options.
+func (r *Recorder) Merge(other *Recorder) {
+	for op, o := range other.ops {
+		s := r.op(op, o.Min)
+		s.Count += o.Count
+		s.Errors += o.Errors
+		s.Sum += o.Sum
+		s.Min = min(s.Min, o.Min)
+		s.Max = max(s.Max, o.Max)
+		s.Latency.Merge(o.Latency)
+		if o.KeyLatency != nil {
+			if s.KeyLatency == nil {
+				s.KeyLatency = NewHistogram(r.opts)
+			}
+			s.Keys += o.Keys
+			s.KeyLatency.Merge(o.KeyLatency)
+		}
+	}
+	for len(r.series) < len(other.series) {
+		r.series = append(r.series, Interval{})
+	}
+	for i, o := range other.series {
+		iv := &r.series[i]
+		iv.Ops += o.Ops
+		iv.Errors += o.Errors
+		iv.Sum += o.Sum
+		iv.Max = max(iv.Max, o.Max)
+	}
+}
+
+func (r *Recorder) Start() time.Time { return r.start }
+
+// Ops returns the names of recorded operations in alphabetical order.
+func (r *Recorder) Ops() []string {
+	names := make([]string, 0, len(r.ops))
+	for op := range r.ops {
+		names = append(names, op)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Recorder) Op(name string) *OpStats { return r.ops[name] }
+
+// Total returns the statistics of all operations combined.
+func (r *Recorder) Total() *OpStats {
+	t := &OpStats{Latency: NewHistogram(r.opts)}
+	first := true
+	for _, s := range r.ops {
+		if first {
+			t.Min = s.Min
+			first = false
+		}
+		t.Count += s.Count
+		t.Errors += s.Errors
+		t.Sum += s.Sum
+		t.Min = min(t.Min, s.Min)
+		t.Max = max(t.Max, s.Max)
+		t.Latency.Merge(s.Latency)
+	}
+	return t
+}
+
+func (r *Recorder) Series() []Interval { return r.series }
+
+func (s *OpStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
@@ -0,0 +1,111 @@
+package stats
+
+import "math"
+
+// WelchTTest tests whether the mean of a is greater than the mean of b
+// without assuming equal variances. It returns the t statistic and the
+// one-sided p-value; p is 1 when either sample has fewer than two values.
+func WelchTTest(a, b []float64) (t, p float64) {
+	if len(a) < 2 || len(b) < 2 {
+		return 0, 1
+	}
+	ma, va := meanVar(a)
+	mb, vb := meanVar(b)
+	na, nb := float64(len(a)), float64(len(b))
+
+	se2 := va/na + vb/nb
+	if se2 == 0 {
+		if ma > mb {
+			return math.Inf(1), 0
+		}
+		return 0, 1
+	}
+	t = (ma - mb) / math.Sqrt(se2)
+	df := se2 * se2 / ((va/na)*(va/na)/(na-1) + (vb/nb)*(vb/nb)/(nb-1))
+
+	return t, studentTail(t, df)
+}
+
+// studentTail is P(T > t) for Student's t with df degrees of freedom.
+func studentTail(t, df float64) float64 {
+	tail := 0.5 * regIncBeta(df/2, 0.5, df/(df+t*t))
+	if t > 0 {
+		return tail
+	}
+	return 1 - tail
+}
+
+func meanVar(xs []float64) (mean, variance float64) {
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, variance / float64(len(xs)-1)
+}
+
+// regIncBeta is the regularized incomplete beta function I_x(a, b),
+// evaluated with the continued fraction from Numerical Recipes.
+func regIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaCF(a, b, x) / a
+	}
+	return 1 - front*betaCF(b, a, 1-x)/b
+}
+
+func betaCF(a, b, x float64) float64 {
+	const (
+		maxIter = 200
+		eps     = 1e-12
+		tiny    = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return h
+}
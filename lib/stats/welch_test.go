@@ -0,0 +1,53 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStudentTail(t *testing.T) {
+	for _, c := range []struct {
+		t, df, p float64
+	}{
+		{0, 10, 0.5},
+		{1, 1, 0.25},         // Cauchy: 1/2 - atan(1)/pi
+		{1, 2, 0.2113249},    // 1/2 - t/(2*sqrt(2+t^2))
+		{1.812461, 10, 0.05}, // t-table critical values
+		{2.228139, 10, 0.025},
+		{2.457262, 30, 0.01},
+		{4.032143, 5, 0.005},
+		{-1.812461, 10, 0.95},
+		{2.5, 7.3, 0.0198251},
+	} {
+		if p := studentTail(c.t, c.df); math.Abs(p-c.p) > 1e-6 {
+			t.Errorf("P(T > %g) with df %g: got %.7f, want %.7f", c.t, c.df, p, c.p)
+		}
+	}
+}
+
+func TestWelchTTest(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		a, b []float64
+		t, p float64
+	}{
+		// equal variances and sizes: df = 2, p = 1/2 - t/(2*sqrt(2+t^2))
+		{"equal variances", []float64{3, 4}, []float64{1, 2}, 2 * math.Sqrt2, 0.0527864},
+		// Welch's example with unequal variances, df = 24.99
+		{"unequal variances",
+			[]float64{27.1, 22.0, 20.8, 23.4, 23.4, 23.5, 25.8, 22.0, 24.8, 20.2, 21.9, 22.1, 22.9, 20.5, 24.4},
+			[]float64{27.5, 21.0, 19.0, 23.6, 17.0, 17.9, 16.9, 20.1, 21.9, 22.6, 23.1, 19.6, 19.0, 21.7, 21.4},
+			2.4553564, 0.0106890},
+		{"lower mean", []float64{1, 2}, []float64{3, 4}, -2 * math.Sqrt2, 1 - 0.0527864},
+		{"too few values", []float64{1}, []float64{1, 2}, 0, 1},
+		{"no variance", []float64{2, 2}, []float64{1, 1}, math.Inf(1), 0},
+	} {
+		tt, p := WelchTTest(c.a, c.b)
+		if math.Abs(tt-c.t) > 1e-6 && !(math.IsInf(c.t, 1) && math.IsInf(tt, 1)) {
+			t.Errorf("%s: t = %.7f, want %.7f", c.name, tt, c.t)
+		}
+		if math.Abs(p-c.p) > 1e-6 {
+			t.Errorf("%s: p = %.7f, want %.7f", c.name, p, c.p)
+		}
+	}
+}
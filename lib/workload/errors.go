@@ -0,0 +1,54 @@
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// Reasons an operation fails for; they label ab_read_errors_total.
+const (
+	ReasonTimeout     = "timeout"
+	ReasonNotFound    = "not_found"
+	ReasonUnavailable = "unavailable"
+	ReasonThrottled   = "throttled"
+	ReasonCanceled    = "canceled"
+	ReasonDecode      = "decode"
+	ReasonOther       = "other"
+)
+
+// Classifier recognizes the errors of a database driver.
+type Classifier interface {
+	// ClassifyError returns the reason err failed for, or "" if the driver
+	// does not know it.
+	ClassifyError(err error) string
+}
+
+// Classify returns the reason err failed for, asking c (which may be nil)
+// before falling back to the errors every driver shares.
+func Classify(c Classifier, err error) string {
+	if c != nil {
+		if reason := c.ClassifyError(err); reason != "" {
+			return reason
+		}
+	}
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return ReasonNotFound
+	case errors.Is(err, context.Canceled):
+		return ReasonCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ReasonTimeout
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, net.ErrClosed):
+		return ReasonUnavailable
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return ReasonDecode
+	}
+	return ReasonOther
+}
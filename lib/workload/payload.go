@@ -0,0 +1,233 @@
+package workload
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+
+	"db-bench/lib/conf"
+)
+
+// Generator builds the rows that Seed and inserts write. A row depends only on
+// its id and the payload config, so every database gets the same dataset and
+// seeded rows can be checked afterwards.
+type Generator struct {
+	cfg conf.Payload
+}
+
+func NewGenerator(cfg conf.Payload) *Generator {
+	return &Generator{cfg: cfg}
+}
+
+// seededVersion is the version of freshly seeded rows; updates pick a new one.
+const seededVersion = 1
+
+// Rule builds the row for id.
+func (g *Generator) Rule(id int64) conf.ExperimentRule {
+	return g.rule(id, seededVersion)
+}
+
+// UpdatedRule builds a modified version of the row for id, as an update to
+// the experiment's targeting would. It keeps the size of the original row.
+func (g *Generator) UpdatedRule(id int64, version int32) conf.ExperimentRule {
+	return g.rule(id, seededVersion+1+version&0x3fffffff)
+}
+
+func (g *Generator) rule(id int64, version int32) conf.ExperimentRule {
+	if g.cfg.Generator != conf.PayloadRealistic {
+		rule := conf.ExperimentRule{
+			ID:             id,
+			ExperimentName: fmt.Sprintf("Test %d", id),
+			TargetingRules: `{"country":"US"}`,
+		}
+		if version != seededVersion {
+			rule.TargetingRules = fmt.Sprintf(`{"country":"US","version":%d}`, version)
+		}
+		return rule
+	}
+
+	b := builder{cfg: g.cfg, rnd: rand.New(rand.NewPCG(uint64(g.cfg.Seed), uint64(id)))}
+	name := b.name(id)
+	size := b.size()
+	t := targeting{
+		Version: version,
+		Salt:    fmt.Sprintf("%016x", b.rnd.Uint64()),
+		Traffic: traffic{Percent: 1 + b.rnd.IntN(100), Layer: pick(b.rnd, layers)},
+	}
+	leaves := g.cfg.MinConditions + b.rnd.IntN(g.cfg.MaxConditions-g.cfg.MinConditions+1)
+	t.Rules = b.group(1, leaves)
+	t.Variants = b.variants()
+
+	data, _ := json.Marshal(t)
+	// Pad with a description up to the target size; the filler is plain
+	// ASCII, so its length in bytes is exact. Rules just short of the target
+	// get a one-byte description and overshoot it by less than the field
+	// itself, rather than stay below it.
+	if gap := size - len(data); gap > 0 {
+		t.Description = b.filler(max(gap-len(`,"description":""`), 1))
+		data, _ = json.Marshal(t)
+	}
+	return conf.ExperimentRule{ID: id, ExperimentName: name, TargetingRules: string(data)}
+}
+
+// Expected returns the row stored under id if it was written by Seed, Insert
+// or Update at the version got carries, so that updated rows still check out.
+func (g *Generator) Expected(id int64, got conf.ExperimentRule) conf.ExperimentRule {
+	var v struct {
+		Version *int32 `json:"version"`
+	}
+	version := int32(seededVersion)
+	if json.Unmarshal([]byte(got.TargetingRules), &v) == nil && v.Version != nil {
+		version = *v.Version
+	}
+	return g.rule(id, version)
+}
+
+// Compare reports how a stored row differs from want. Targeting rules are
+// compared as JSON, since JSON columns normalize the text.
+func Compare(got, want conf.ExperimentRule) error {
+	if got.ID != want.ID {
+		return fmt.Errorf("got row %d, want %d", got.ID, want.ID)
+	}
+	if got.ExperimentName != want.ExperimentName {
+		return fmt.Errorf("experiment_name is %q, want %q", got.ExperimentName, want.ExperimentName)
+	}
+	var g, w any
+	if err := json.Unmarshal([]byte(got.TargetingRules), &g); err != nil {
+		return fmt.Errorf("targeting_rules is not valid JSON: %w", err)
+	}
+	if err := json.Unmarshal([]byte(want.TargetingRules), &w); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(g, w) {
+		return fmt.Errorf("targeting_rules differ from the generator (%d bytes stored, %d expected)",
+			len(got.TargetingRules), len(want.TargetingRules))
+	}
+	return nil
+}
+
+type targeting struct {
+	Version     int32     `json:"version"`
+	Salt        string    `json:"salt"`
+	Traffic     traffic   `json:"traffic"`
+	Rules       condition `json:"rules"`
+	Variants    []variant `json:"variants"`
+	Description string    `json:"description,omitempty"`
+}
+
+type traffic struct {
+	Percent int    `json:"percent"`
+	Layer   string `json:"layer"`
+}
+
+// condition is either a leaf (Attr, Op, Values) or a group of conditions
+// joined by Op ("and" / "or").
+type condition struct {
+	Attr       string      `json:"attr,omitempty"`
+	Op         string      `json:"op"`
+	Values     []string    `json:"values,omitempty"`
+	Conditions []condition `json:"conditions,omitempty"`
+}
+
+type variant struct {
+	Name   string            `json:"name"`
+	Weight int               `json:"weight"`
+	Params map[string]string `json:"params"`
+}
+
+var (
+	attributes = []string{"country", "platform", "app_version", "segment", "language",
+		"device", "region", "plan", "cohort", "os_version"}
+	leafOps  = []string{"in", "not_in", "eq", "gte", "lt"}
+	layers   = []string{"checkout", "search", "onboarding", "pricing", "feed", "notifications"}
+	variants = []string{"control", "treatment_a", "treatment_b", "treatment_c"}
+	words    = []string{"button", "color", "layout", "banner", "price", "discount", "flow", "copy",
+		"ranking", "model", "cache", "timeout", "badge", "modal", "sort", "filter"}
+)
+
+type builder struct {
+	cfg conf.Payload
+	rnd *rand.Rand
+}
+
+func (b *builder) name(id int64) string {
+	n := id
+	if b.cfg.NameCardinality > 0 {
+		n = 1 + int64(b.rnd.IntN(b.cfg.NameCardinality))
+	}
+	return fmt.Sprintf("%s-%s-%d", words[n%int64(len(words))], words[(n/int64(len(words)))%int64(len(words))], n)
+}
+
+func (b *builder) size() int {
+	s := b.cfg.Size
+	switch s.Dist {
+	case "uniform":
+		return s.Min + b.rnd.IntN(s.Max-s.Min+1)
+	case "normal":
+		return min(max(s.Mean+int(b.rnd.NormFloat64()*float64(s.StdDev)), s.Min), s.Max)
+	default:
+		return s.Mean
+	}
+}
+
+// group builds a group holding leaves leaf conditions, nesting subgroups
+// down to MaxDepth.
+func (b *builder) group(depth, leaves int) condition {
+	c := condition{Op: pick(b.rnd, []string{"and", "or"})}
+	for leaves > 0 {
+		if depth < b.cfg.MaxDepth && leaves > 1 && b.rnd.IntN(3) == 0 {
+			n := 1 + b.rnd.IntN(leaves)
+			c.Conditions = append(c.Conditions, b.group(depth+1, n))
+			leaves -= n
+			continue
+		}
+		c.Conditions = append(c.Conditions, b.leaf())
+		leaves--
+	}
+	return c
+}
+
+func (b *builder) leaf() condition {
+	c := condition{Attr: pick(b.rnd, attributes), Op: pick(b.rnd, leafOps)}
+	n := 1
+	if c.Op == "in" || c.Op == "not_in" {
+		n = 1 + b.rnd.IntN(5)
+	}
+	for range n {
+		c.Values = append(c.Values, fmt.Sprintf("%s_%d", c.Attr, b.rnd.IntN(b.cfg.Cardinality)))
+	}
+	return c
+}
+
+func (b *builder) variants() []variant {
+	n := 2 + b.rnd.IntN(len(variants)-1)
+	vs := make([]variant, n)
+	left := 100
+	for i := range vs {
+		vs[i] = variant{Name: variants[i], Weight: left / (n - i), Params: map[string]string{}}
+		left -= vs[i].Weight
+		for range 1 + b.rnd.IntN(3) {
+			vs[i].Params[pick(b.rnd, words)] = fmt.Sprintf("%s_%d", pick(b.rnd, words), b.rnd.IntN(b.cfg.Cardinality))
+		}
+	}
+	return vs
+}
+
+// filler returns n bytes of space-separated words.
+func (b *builder) filler(n int) string {
+	var sb strings.Builder
+	sb.Grow(n + 16)
+	for sb.Len() < n {
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(pick(b.rnd, words))
+	}
+	return sb.String()[:n]
+}
+
+func pick(rnd *rand.Rand, xs []string) string {
+	return xs[rnd.IntN(len(xs))]
+}
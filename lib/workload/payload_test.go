@@ -0,0 +1,134 @@
+package workload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"db-bench/lib/conf"
+)
+
+const testRows = 2000
+
+func testPayload(dist string) conf.Payload {
+	return conf.Payload{
+		Generator:     conf.PayloadRealistic,
+		Seed:          1,
+		Size:          conf.PayloadSize{Dist: dist, Min: 1024, Max: 20480, Mean: 4096, StdDev: 2048},
+		MaxDepth:      3,
+		MinConditions: 2,
+		MaxConditions: 12,
+		Cardinality:   50,
+	}
+}
+
+func TestRuleDeterministic(t *testing.T) {
+	for _, cfg := range []conf.Payload{{Generator: conf.PayloadMinimal}, testPayload("normal")} {
+		t.Run(cfg.Generator, func(t *testing.T) {
+			a, b := NewGenerator(cfg), NewGenerator(cfg)
+			for id := int64(1); id <= testRows; id++ {
+				if ra, rb := a.Rule(id), b.Rule(id); ra != rb {
+					t.Fatalf("id %d: generators disagree:\n%+v\n%+v", id, ra, rb)
+				}
+				if ra, rb := a.UpdatedRule(id, 7), b.UpdatedRule(id, 7); ra != rb {
+					t.Fatalf("id %d: updated rows disagree:\n%+v\n%+v", id, ra, rb)
+				}
+			}
+		})
+	}
+}
+
+// TestRuleGolden pins the generated rows: any change to them makes every
+// existing dataset fail verify and read validation.
+func TestRuleGolden(t *testing.T) {
+	if got := NewGenerator(conf.Payload{Generator: conf.PayloadMinimal}).Rule(42); got != (conf.ExperimentRule{
+		ID: 42, ExperimentName: "Test 42", TargetingRules: `{"country":"US"}`}) {
+		t.Errorf("minimal row 42 is %+v", got)
+	}
+
+	g := NewGenerator(testPayload("normal"))
+	h := sha256.New()
+	for id := int64(1); id <= 100; id++ {
+		r := g.Rule(id)
+		h.Write([]byte(r.ExperimentName))
+		h.Write([]byte(r.TargetingRules))
+	}
+	const want = "7b1b3b650f7c70f07db6d6349a778a0d867be1b50a9e927cd96ede1a3482fe9d"
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		t.Errorf("realistic rows 1..100 hash to %s, want %s", got, want)
+	}
+}
+
+func TestRuleSize(t *testing.T) {
+	for _, dist := range []string{"fixed", "uniform", "normal"} {
+		t.Run(dist, func(t *testing.T) {
+			cfg := testPayload(dist)
+			g := NewGenerator(cfg)
+			for id := int64(1); id <= testRows; id++ {
+				r := g.Rule(id)
+				n := len(r.TargetingRules)
+				if dist == "fixed" && n != cfg.Size.Mean {
+					t.Fatalf("id %d: %d bytes, want %d", id, n, cfg.Size.Mean)
+				}
+				if n < cfg.Size.Min || n > cfg.Size.Max {
+					t.Fatalf("id %d: %d bytes outside [%d, %d]", id, n, cfg.Size.Min, cfg.Size.Max)
+				}
+				if !json.Valid([]byte(r.TargetingRules)) {
+					t.Fatalf("id %d: targeting rules are not valid JSON", id)
+				}
+				// Padded rows keep their size exactly; rows whose rules
+				// alone exceed the target only differ in the version digits.
+				if u := g.UpdatedRule(id, int32(id)); abs(len(u.TargetingRules)-n) > 9 {
+					t.Fatalf("id %d: update changed the size from %d to %d bytes", id, n, len(u.TargetingRules))
+				}
+			}
+		})
+	}
+}
+
+func abs(x int) int { return max(x, -x) }
+
+func TestExpected(t *testing.T) {
+	for _, cfg := range []conf.Payload{{Generator: conf.PayloadMinimal}, testPayload("normal")} {
+		t.Run(cfg.Generator, func(t *testing.T) {
+			g := NewGenerator(cfg)
+			for id := int64(1); id <= testRows; id++ {
+				seeded := g.Rule(id)
+				if err := Compare(seeded, g.Expected(id, seeded)); err != nil {
+					t.Fatalf("id %d seeded: %v", id, err)
+				}
+				updated := g.UpdatedRule(id, int32(id))
+				if err := Compare(updated, g.Expected(id, updated)); err != nil {
+					t.Fatalf("id %d updated: %v", id, err)
+				}
+				if Compare(updated, seeded) == nil {
+					t.Fatalf("id %d: the update left the row unchanged", id)
+				}
+				if Compare(g.Rule(id+1), g.Expected(id, g.Rule(id+1))) == nil {
+					t.Fatalf("id %d: the row of id %d checks out as its own", id, id+1)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareNormalizedJSON(t *testing.T) {
+	want := conf.ExperimentRule{ID: 1, ExperimentName: "a", TargetingRules: `{"a":1,"b":[1,2]}`}
+	for _, c := range []struct {
+		name string
+		got  conf.ExperimentRule
+		ok   bool
+	}{
+		{"identical", want, true},
+		{"reformatted", conf.ExperimentRule{ID: 1, ExperimentName: "a", TargetingRules: `{"b": [1, 2], "a": 1}`}, true},
+		{"other id", conf.ExperimentRule{ID: 2, ExperimentName: "a", TargetingRules: want.TargetingRules}, false},
+		{"other name", conf.ExperimentRule{ID: 1, ExperimentName: "b", TargetingRules: want.TargetingRules}, false},
+		{"other value", conf.ExperimentRule{ID: 1, ExperimentName: "a", TargetingRules: `{"a":1,"b":[2,1]}`}, false},
+		{"invalid JSON", conf.ExperimentRule{ID: 1, ExperimentName: "a", TargetingRules: `{"a":`}, false},
+	} {
+		if err := Compare(c.got, want); (err == nil) != c.ok {
+			t.Errorf("%s: Compare = %v", c.name, err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Querier finds rows by an attribute of their targeting rules.
+type Querier interface {
+	// Query reads up to conf.Config.Query.Limit rows whose attribute at
+	// conf.Config.Query.Path equals value, fetching as much of each row as
+	// Read does, and returns the number of rows.
+	Query(ctx context.Context, value any) (rows int, err error)
+}
+
+// Attribute returns the value at path in the targeting rules of the seeded
+// row for id: a string, a float64 or a bool as encoding/json decodes them.
+// Drawing ids from the key distribution makes hot rows hot values too.
+func (g *Generator) Attribute(id int64, path []string) (any, error) {
+	var v any
+	if err := json.Unmarshal([]byte(g.Rule(id).TargetingRules), &v); err != nil {
+		return nil, err
+	}
+	for _, key := range path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("targeting_rules of %d have no attribute %q", id, key)
+		}
+		if v, ok = obj[key]; !ok {
+			return nil, fmt.Errorf("targeting_rules of %d have no attribute %q", id, key)
+		}
+	}
+	switch v.(type) {
+	case string, float64, bool:
+		return v, nil
+	}
+	return nil, fmt.Errorf("attribute of %d is not a scalar", id)
+}
+
+// Nested wraps value in objects along path, as in a JSON containment query.
+func Nested(path []string, value any) any {
+	for i := len(path) - 1; i >= 0; i-- {
+		value = map[string]any{path[i]: value}
+	}
+	return value
+}
@@ -0,0 +1,51 @@
+package workload
+
+import (
+	"context"
+
+	"db-bench/lib/conf"
+)
+
+// ScanQuery selects one page of rows in id order.
+type ScanQuery struct {
+	// Mode is conf.ScanRange, conf.ScanKeyset or conf.ScanOffset.
+	Mode string
+	// From is the first id of a range, or the id a keyset page follows.
+	From int64
+	// Offset is the number of rows an offset page skips.
+	Offset int
+	// Limit is the page length; a range covers ids From..From+Limit-1.
+	Limit int
+}
+
+// To is the id just past a range.
+func (q ScanQuery) To() int64 { return q.From + int64(q.Limit) }
+
+// Scanner reads pages of consecutive rows.
+type Scanner interface {
+	// Scan reads the page q selects, fetching as much of each row as Read
+	// does, and returns the number of rows and the id of the last one.
+	Scan(ctx context.Context, q ScanQuery) (rows int, last int64, err error)
+}
+
+// ScanPages reads up to pages pages starting with q, stopping early at the
+// end of the table, and returns the number of rows read.
+func ScanPages(ctx context.Context, s Scanner, q ScanQuery, pages int) (int, error) {
+	total := 0
+	for range pages {
+		n, last, err := s.Scan(ctx, q)
+		total += n
+		if err != nil || n == 0 || (q.Mode != conf.ScanRange && n < q.Limit) {
+			return total, err
+		}
+		switch q.Mode {
+		case conf.ScanRange:
+			q.From += int64(q.Limit)
+		case conf.ScanKeyset:
+			q.From = last
+		case conf.ScanOffset:
+			q.Offset += q.Limit
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,23 @@
+package workload
+
+import "encoding/binary"
+
+// UUID derives a stable, random-looking version 4 UUID from id for schemas
+// keyed by UUID: consecutive ids land far apart in the key space, as keys
+// generated by clients do.
+func UUID(id int64) [16]byte {
+	var u [16]byte
+	binary.BigEndian.PutUint64(u[:8], splitmix64(uint64(id)))
+	binary.BigEndian.PutUint64(u[8:], splitmix64(^uint64(id)))
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+// splitmix64 is a bijective mixer of 64-bit integers.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
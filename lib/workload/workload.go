@@ -0,0 +1,172 @@
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"db-bench/lib/conf"
+)
+
+// ErrNotFound is returned by an Operator when the requested id does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// Operator is the set of single-record operations every backend implements.
+// Implementations must be safe for concurrent use by all workers. Read
+// fetches as much of the row as conf.Config.Read.Projection asks for.
+// Read and Update fail with ErrNotFound on a missing id and never create it;
+// Delete of a missing id succeeds, as deleting twice leaves the same data.
+type Operator interface {
+	Read(ctx context.Context, id int64) error
+	Update(ctx context.Context, rule conf.ExperimentRule) error
+	Insert(ctx context.Context, rule conf.ExperimentRule) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// BatchReader reads several records in one request.
+type BatchReader interface {
+	// ReadMany fetches as much of each record as Read does. It fails with
+	// ErrNotFound if any of ids is missing.
+	ReadMany(ctx context.Context, ids []int64) error
+}
+
+// ReadRow serves Read for the row and decode projections (conf.Read) with
+// get, which fetches the whole row.
+func ReadRow(ctx context.Context, projection string, id int64, get func(context.Context, int64) (conf.ExperimentRule, error)) error {
+	rule, err := get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return DecodeRow(projection, rule)
+}
+
+// DecodeRow decodes a fetched row if projection asks for it.
+func DecodeRow(projection string, rule conf.ExperimentRule) error {
+	if projection != conf.ProjectionDecode {
+		return nil
+	}
+	return Decode(rule)
+}
+
+// ReadParallel serves a multi-get with one concurrent read per id, as a
+// client of a database without a native one would. It returns the error of
+// the first failed id.
+func ReadParallel(ctx context.Context, ids []int64, read func(context.Context, int64) error) error {
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = read(ctx, id)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Found is the outcome of a multi-get of want ids that returned found rows.
+func Found(found, want int) error {
+	if found < want {
+		return fmt.Errorf("%d of %d records: %w", want-found, want, ErrNotFound)
+	}
+	return nil
+}
+
+// Decode parses the targeting rules of a row read with the decode projection,
+// as an application consuming the row would.
+func Decode(rule conf.ExperimentRule) error {
+	var rules any
+	if err := json.Unmarshal([]byte(rule.TargetingRules), &rules); err != nil {
+		return fmt.Errorf("failed to decode targeting_rules of %d: %w", rule.ID, err)
+	}
+	return nil
+}
+
+type Op string
+
+const (
+	OpRead            Op = "read"
+	OpUpdate          Op = "update"
+	OpInsert          Op = "insert"
+	OpDelete          Op = "delete"
+	OpReadModifyWrite Op = "read_modify_write"
+	OpMultiGet        Op = "multiget"
+	OpScan            Op = "scan"
+	OpQuery           Op = "query"
+)
+
+// Ops lists every operation in a stable order.
+var Ops = []Op{OpRead, OpUpdate, OpInsert, OpDelete, OpReadModifyWrite, OpMultiGet, OpScan, OpQuery}
+
+// Mix picks the next operation according to the configured proportions.
+type Mix struct {
+	ops     []Op
+	weights []float64
+	total   float64
+}
+
+func NewMix(w conf.Workload) *Mix {
+	m := &Mix{}
+	for _, op := range Ops {
+		if p := Proportion(w, op); p > 0 {
+			m.ops = append(m.ops, op)
+			m.weights = append(m.weights, p)
+			m.total += p
+		}
+	}
+	return m
+}
+
+func Proportion(w conf.Workload, op Op) float64 {
+	switch op {
+	case OpRead:
+		return w.Read
+	case OpUpdate:
+		return w.Update
+	case OpInsert:
+		return w.Insert
+	case OpDelete:
+		return w.Delete
+	case OpReadModifyWrite:
+		return w.ReadModifyWrite
+	case OpMultiGet:
+		return w.MultiGet
+	case OpScan:
+		return w.Scan
+	case OpQuery:
+		return w.Query
+	}
+	return 0
+}
+
+func (m *Mix) Pick(rnd *rand.Rand) Op {
+	if len(m.ops) == 1 {
+		return m.ops[0]
+	}
+	x := rnd.Float64() * m.total
+	for i, w := range m.weights {
+		if x < w {
+			return m.ops[i]
+		}
+		x -= w
+	}
+	return m.ops[len(m.ops)-1]
+}
+
+func (m *Mix) String() string {
+	parts := make([]string, len(m.ops))
+	for i, op := range m.ops {
+		parts[i] = fmt.Sprintf("%s=%.0f%%", op, 100*m.weights[i]/m.total)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,27 @@
+package ydb
+
+import (
+	"db-bench/lib/backend"
+
+	"github.com/ydb-platform/ydb-go-sdk/v3/table"
+)
+
+var consistencyLevels = []backend.ConfigKey{
+	{Key: "serializable", Description: "serializable read-write transaction (default)"},
+	{Key: "online", Description: "online read-only: the latest committed data of each shard"},
+	{Key: "stale", Description: "stale read-only: data possibly a few seconds old"},
+	{Key: "snapshot", Description: "snapshot read-only: a consistent snapshot of all shards"},
+}
+
+// readTxControl returns the transaction reads run in at the given level.
+func readTxControl(level string) *table.TransactionControl {
+	switch level {
+	case "online":
+		return table.OnlineReadOnlyTxControl()
+	case "stale":
+		return table.StaleReadOnlyTxControl()
+	case "snapshot":
+		return table.SnapshotReadOnlyTxControl()
+	}
+	return table.DefaultTxControl()
+}
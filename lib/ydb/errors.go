@@ -0,0 +1,25 @@
+package ydb
+
+import (
+	"db-bench/lib/workload"
+
+	"github.com/ydb-platform/ydb-go-genproto/protos/Ydb"
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+	grpcCodes "google.golang.org/grpc/codes"
+)
+
+func (t *YDBTester) ClassifyError(err error) string {
+	switch {
+	case ydb.IsOperationError(err, Ydb.StatusIds_TIMEOUT), ydb.IsTransportError(err, grpcCodes.DeadlineExceeded):
+		return workload.ReasonTimeout
+	case ydb.IsOperationErrorOverloaded(err), ydb.IsRatelimiterAcquireError(err),
+		ydb.IsTransportError(err, grpcCodes.ResourceExhausted):
+		return workload.ReasonThrottled
+	case ydb.IsOperationErrorUnavailable(err), ydb.IsOperationError(err, Ydb.StatusIds_BAD_SESSION, Ydb.StatusIds_SESSION_EXPIRED),
+		ydb.IsTransportError(err, grpcCodes.Unavailable):
+		return workload.ReasonUnavailable
+	case ydb.IsOperationError(err, Ydb.StatusIds_CANCELLED), ydb.IsTransportError(err, grpcCodes.Canceled):
+		return workload.ReasonCanceled
+	}
+	return ""
+}
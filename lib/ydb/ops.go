@@ -0,0 +1,314 @@
+package ydb
+
+import (
+	"context"
+	"db-bench/lib/conf"
+	"db-bench/lib/workload"
+	"fmt"
+
+	"github.com/ydb-platform/ydb-go-sdk/v3/table"
+	"github.com/ydb-platform/ydb-go-sdk/v3/table/types"
+)
+
+type queries struct {
+	read, get, update, insert, delete string
+	readMany, getMany                 string
+	// by scan mode, see scanParams
+	scanIDs, scanRows map[string]string
+	// $value is the attribute as text, $limit the number of rows
+	queryIDs, queryRows string
+}
+
+func newQueries(tablePath, attribute string) queries {
+	table := "`" + tablePath + "`"
+	return queries{
+		read: fmt.Sprintf(`
+			DECLARE $id AS Int64;
+			SELECT id
+			FROM %s
+			WHERE id = $id;
+		`, table),
+		get: fmt.Sprintf(`
+			DECLARE $id AS Int64;
+			SELECT id, experiment_name, targeting_rules
+			FROM %s
+			WHERE id = $id;
+		`, table),
+		update: fmt.Sprintf(`
+			DECLARE $id AS Int64;
+			DECLARE $experiment_name AS Utf8;
+			DECLARE $targeting_rules AS Json;
+			SELECT COUNT(*) FROM %[1]s WHERE id = $id;
+			UPDATE %[1]s
+			SET experiment_name = $experiment_name, targeting_rules = $targeting_rules
+			WHERE id = $id;
+		`, table),
+		insert: fmt.Sprintf(`
+			DECLARE $id AS Int64;
+			DECLARE $experiment_name AS Utf8;
+			DECLARE $targeting_rules AS Json;
+			INSERT INTO %s (id, experiment_name, targeting_rules)
+			VALUES ($id, $experiment_name, $targeting_rules);
+		`, table),
+		delete: fmt.Sprintf(`
+			DECLARE $id AS Int64;
+			DELETE FROM %s WHERE id = $id;
+		`, table),
+		readMany: fmt.Sprintf(`
+			DECLARE $ids AS List<Int64>;
+			SELECT id
+			FROM %s
+			WHERE id IN $ids;
+		`, table),
+		getMany: fmt.Sprintf(`
+			DECLARE $ids AS List<Int64>;
+			SELECT id, experiment_name, targeting_rules
+			FROM %s
+			WHERE id IN $ids;
+		`, table),
+
+		scanIDs:  scanQueries(table, "id"),
+		scanRows: scanQueries(table, "id, experiment_name, targeting_rules"),
+
+		queryIDs:  attributeQuery(table, "id", attribute),
+		queryRows: attributeQuery(table, "id, experiment_name, targeting_rules", attribute),
+	}
+}
+
+// attributeQuery filters on JSON_VALUE, which YDB cannot index: every
+// query reads the table until it has found $limit rows.
+func attributeQuery(table, columns, attribute string) string {
+	return fmt.Sprintf(`
+		DECLARE $value AS Utf8;
+		DECLARE $limit AS Uint64;
+		SELECT %s
+		FROM %s
+		WHERE JSON_VALUE(targeting_rules, "$.%s") = $value
+		LIMIT $limit;
+	`, columns, table, attribute)
+}
+
+func scanQueries(table, columns string) map[string]string {
+	return map[string]string{
+		conf.ScanRange: fmt.Sprintf(`
+			DECLARE $from AS Int64;
+			DECLARE $to AS Int64;
+			SELECT %s
+			FROM %s
+			WHERE id >= $from AND id < $to
+			ORDER BY id;
+		`, columns, table),
+		conf.ScanKeyset: fmt.Sprintf(`
+			DECLARE $from AS Int64;
+			DECLARE $limit AS Uint64;
+			SELECT %s
+			FROM %s
+			WHERE id > $from
+			ORDER BY id
+			LIMIT $limit;
+		`, columns, table),
+		conf.ScanOffset: fmt.Sprintf(`
+			DECLARE $offset AS Uint64;
+			DECLARE $limit AS Uint64;
+			SELECT %s
+			FROM %s
+			ORDER BY id
+			LIMIT $limit OFFSET $offset;
+		`, columns, table),
+	}
+}
+
+func scanParams(q workload.ScanQuery) *table.QueryParameters {
+	switch q.Mode {
+	case conf.ScanRange:
+		return table.NewQueryParameters(
+			table.ValueParam("$from", types.Int64Value(q.From)),
+			table.ValueParam("$to", types.Int64Value(q.To())),
+		)
+	case conf.ScanKeyset:
+		return table.NewQueryParameters(
+			table.ValueParam("$from", types.Int64Value(q.From)),
+			table.ValueParam("$limit", types.Uint64Value(uint64(q.Limit))),
+		)
+	default:
+		return table.NewQueryParameters(
+			table.ValueParam("$offset", types.Uint64Value(uint64(q.Offset))),
+			table.ValueParam("$limit", types.Uint64Value(uint64(q.Limit))),
+		)
+	}
+}
+
+func (t *YDBTester) Read(ctx context.Context, id int64) error {
+	if t.cfg.Read.Projection != conf.ProjectionID {
+		return workload.ReadRow(ctx, t.cfg.Read.Projection, id, t.Get)
+	}
+	return t.db.Table().Do(ctx, func(ctx context.Context, s table.Session) error {
+		_, res, err := s.Execute(ctx, t.readTx, t.q.read,
+			table.NewQueryParameters(
+				table.ValueParam("$id", types.Int64Value(id)),
+			),
+		)
+		if err != nil {
+			return err
+		}
+		defer res.Close()
+
+		if res.NextResultSet(ctx) && res.NextRow() {
+			return nil
+		}
+		return workload.ErrNotFound
+	})
+}
+
+func (t *YDBTester) ReadMany(ctx context.Context, ids []int64) error {
+	query := t.q.getMany
+	if t.cfg.Read.Projection == conf.ProjectionID {
+		query = t.q.readMany
+	}
+	values := make([]types.Value, len(ids))
+	for i, id := range ids {
+		values[i] = types.Int64Value(id)
+	}
+	params := table.NewQueryParameters(table.ValueParam("$ids", types.ListValue(values...)))
+	found := 0
+	err := t.db.Table().Do(ctx, func(ctx context.Context, s table.Session) error {
+		found = 0
+		_, res, err := s.Execute(ctx, t.readTx, query, params)
+		if err != nil {
+			return err
+		}
+		defer res.Close()
+
+		for res.NextResultSet(ctx) {
+			for res.NextRow() {
+				found++
+				if t.cfg.Read.Projection == conf.ProjectionID {
+					continue
+				}
+				var rule conf.ExperimentRule
+				if err := res.ScanWithDefaults(&rule.ID, &rule.ExperimentName, &rule.TargetingRules); err != nil {
+					return err
+				}
+				if err := workload.DecodeRow(t.cfg.Read.Projection, rule); err != nil {
+					return err
+				}
+			}
+		}
+		return res.Err()
+	})
+	if err != nil {
+		return err
+	}
+	return workload.Found(found, len(ids))
+}
+
+func (t *YDBTester) Scan(ctx context.Context, q workload.ScanQuery) (int, int64, error) {
+	query := t.q.scanRows[q.Mode]
+	if t.cfg.Read.Projection == conf.ProjectionID {
+		query = t.q.scanIDs[q.Mode]
+	}
+	return t.readRows(ctx, query, scanParams(q))
+}
+
+func (t *YDBTester) Query(ctx context.Context, value any) (int, error) {
+	query := t.q.queryRows
+	if t.cfg.Read.Projection == conf.ProjectionID {
+		query = t.q.queryIDs
+	}
+	// JSON_VALUE yields text, also for numbers and booleans.
+	n, _, err := t.readRows(ctx, query, table.NewQueryParameters(
+		table.ValueParam("$value", types.UTF8Value(fmt.Sprint(value))),
+		table.ValueParam("$limit", types.Uint64Value(uint64(t.cfg.Query.Limit))),
+	))
+	return n, err
+}
+
+// readRows runs a query for the scan or query columns the projection asks
+// for, returning the number of rows and the last id.
+func (t *YDBTester) readRows(ctx context.Context, query string, params *table.QueryParameters) (int, int64, error) {
+	var n int
+	var rule conf.ExperimentRule
+	err := t.db.Table().Do(ctx, func(ctx context.Context, s table.Session) error {
+		n = 0
+		_, res, err := s.Execute(ctx, t.readTx, query, params)
+		if err != nil {
+			return err
+		}
+		defer res.Close()
+
+		for res.NextResultSet(ctx) {
+			for res.NextRow() {
+				if t.cfg.Read.Projection == conf.ProjectionID {
+					err = res.ScanWithDefaults(&rule.ID)
+				} else {
+					err = res.ScanWithDefaults(&rule.ID, &rule.ExperimentName, &rule.TargetingRules)
+				}
+				if err == nil {
+					err = workload.DecodeRow(t.cfg.Read.Projection, rule)
+				}
+				if err != nil {
+					return err
+				}
+				n++
+			}
+		}
+		return res.Err()
+	})
+	return n, rule.ID, err
+}
+
+// Update counts the row in the same transaction as the UPDATE, which
+// silently does nothing for a missing id.
+func (t *YDBTester) Update(ctx context.Context, rule conf.ExperimentRule) error {
+	return t.db.Table().Do(ctx, func(ctx context.Context, s table.Session) error {
+		_, res, err := s.Execute(ctx, table.DefaultTxControl(), t.q.update, ruleParams(rule))
+		if err != nil {
+			return err
+		}
+		defer res.Close()
+
+		var n uint64
+		if res.NextResultSet(ctx) && res.NextRow() {
+			if err := res.ScanWithDefaults(&n); err != nil {
+				return err
+			}
+		}
+		if err := res.Err(); err != nil {
+			return err
+		}
+		if n == 0 {
+			return workload.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (t *YDBTester) Insert(ctx context.Context, rule conf.ExperimentRule) error {
+	return t.execute(ctx, t.q.insert, ruleParams(rule))
+}
+
+func (t *YDBTester) Delete(ctx context.Context, id int64) error {
+	return t.execute(ctx, t.q.delete, table.NewQueryParameters(
+		table.ValueParam("$id", types.Int64Value(id)),
+	))
+}
+
+// execute runs a write. Writes always use a serializable read-write
+// transaction; the consistency level only applies to reads (t.readTx).
+func (t *YDBTester) execute(ctx context.Context, query string, params *table.QueryParameters) error {
+	return t.db.Table().Do(ctx, func(ctx context.Context, s table.Session) error {
+		_, res, err := s.Execute(ctx, table.DefaultTxControl(), query, params)
+		if err != nil {
+			return err
+		}
+		return res.Close()
+	})
+}
+
+func ruleParams(rule conf.ExperimentRule) *table.QueryParameters {
+	return table.NewQueryParameters(
+		table.ValueParam("$id", types.Int64Value(rule.ID)),
+		table.ValueParam("$experiment_name", types.UTF8Value(rule.ExperimentName)),
+		table.ValueParam("$targeting_rules", types.JSONValue(rule.TargetingRules)),
+	)
+}
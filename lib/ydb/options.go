@@ -0,0 +1,92 @@
+package ydb
+
+import (
+	"fmt"
+	"time"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+	"github.com/ydb-platform/ydb-go-sdk/v3/balancers"
+	"github.com/ydb-platform/ydb-go-sdk/v3/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+var driverOptions = []backend.ConfigKey{
+	{Key: "sessionPoolSize", Description: "table sessions in the pool (default 50)"},
+	{Key: "sessionIdleThreshold", Description: "close sessions idle for this long"},
+	{Key: "dialTimeout", Description: "dial timeout (default connect_timeout)"},
+	{Key: "connectionTTL", Description: "close gRPC connections idle for this long"},
+	{Key: "balancer", Description: "random (default), roundRobin or single"},
+	{Key: "preferLocalDC", Description: "true: send requests to nodes of the client's datacenter"},
+	{Key: "keepaliveTime", Description: "gRPC keepalive ping interval (default off)"},
+	{Key: "keepaliveTimeout", Description: "wait for a keepalive ack this long (default 20s)"},
+}
+
+// openOptions maps ydb.options onto ydb.Open options.
+func openOptions(cfg *conf.Config) ([]ydb.Option, error) {
+	o := cfg.Options
+	dialTimeout, err := o.Duration("dialTimeout", cfg.ConnectTimeout)
+	if err != nil {
+		return nil, err
+	}
+	opts := []ydb.Option{ydb.WithDialTimeout(dialTimeout)}
+	if _, ok := o["sessionPoolSize"]; ok {
+		n, err := o.Int("sessionPoolSize", 0)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, ydb.WithSessionPoolSizeLimit(n))
+	}
+	for key, option := range map[string]func(time.Duration) ydb.Option{
+		"sessionIdleThreshold": ydb.WithSessionPoolIdleThreshold,
+		"connectionTTL":        ydb.WithConnectionTTL,
+	} {
+		if _, ok := o[key]; !ok {
+			continue
+		}
+		d, err := o.Duration(key, 0)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, option(d))
+	}
+
+	preferLocal, err := o.Bool("preferLocalDC", false)
+	if err != nil {
+		return nil, err
+	}
+	balancer := balancers.RandomChoice()
+	switch b := o.Setting("balancer", "random"); b {
+	case "random":
+	case "roundRobin":
+		balancer = balancers.RoundRobin()
+	case "single":
+		balancer = balancers.SingleConn()
+	default:
+		return nil, fmt.Errorf("unknown balancer %q (want random, roundRobin or single)", b)
+	}
+	if preferLocal {
+		balancer = balancers.PreferLocalDC(balancer)
+	}
+	opts = append(opts, ydb.WithBalancer(balancer))
+
+	keepaliveTime, err := o.Duration("keepaliveTime", 0)
+	if err != nil {
+		return nil, err
+	}
+	keepaliveTimeout, err := o.Duration("keepaliveTimeout", 0)
+	if err != nil {
+		return nil, err
+	}
+	if keepaliveTime > 0 {
+		opts = append(opts, ydb.With(config.WithGrpcOptions(grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                keepaliveTime,
+			Timeout:             keepaliveTimeout,
+			PermitWithoutStream: true,
+		}))))
+	}
+	return opts, nil
+}
@@ -0,0 +1,94 @@
+package ydb
+
+import (
+	"fmt"
+
+	"db-bench/lib/backend"
+	"db-bench/lib/conf"
+
+	"github.com/ydb-platform/ydb-go-sdk/v3/table/options"
+)
+
+var schemaSettings = []backend.ConfigKey{
+	{Key: "autoPartitioningBySize", Description: "split partitions that outgrow partitionSizeMB (AUTO_PARTITIONING_BY_SIZE)"},
+	{Key: "autoPartitioningByLoad", Description: "split partitions under heavy load (AUTO_PARTITIONING_BY_LOAD)"},
+	{Key: "partitionSizeMB", Description: "AUTO_PARTITIONING_PARTITION_SIZE_MB"},
+	{Key: "minPartitions", Description: "AUTO_PARTITIONING_MIN_PARTITIONS_COUNT"},
+	{Key: "maxPartitions", Description: "AUTO_PARTITIONING_MAX_PARTITIONS_COUNT"},
+	{Key: "uniformPartitions", Description: "number of partitions the table starts with (UNIFORM_PARTITIONS)"},
+	{Key: "compression", Description: "column compression: none (default) or lz4"},
+}
+
+// tableOptions turns the schema variant into CREATE TABLE options; settings
+// left out keep the server defaults.
+func tableOptions(s conf.Schema) ([]options.CreateTableOption, error) {
+	var opts []options.CreateTableOption
+	var partitioning []options.PartitioningSettingsOption
+	for _, f := range []struct {
+		key string
+		opt func(options.FeatureFlag) options.PartitioningSettingsOption
+	}{
+		{"autoPartitioningBySize", options.WithPartitioningBySize},
+		{"autoPartitioningByLoad", options.WithPartitioningByLoad},
+	} {
+		if _, ok := s.Settings[f.key]; !ok {
+			continue
+		}
+		on, err := s.Bool(f.key, false)
+		if err != nil {
+			return nil, err
+		}
+		flag := options.FeatureDisabled
+		if on {
+			flag = options.FeatureEnabled
+		}
+		partitioning = append(partitioning, f.opt(flag))
+	}
+	for _, n := range []struct {
+		key string
+		opt func(uint64) options.PartitioningSettingsOption
+	}{
+		{"partitionSizeMB", options.WithPartitionSizeMb},
+		{"minPartitions", options.WithMinPartitionsCount},
+		{"maxPartitions", options.WithMaxPartitionsCount},
+	} {
+		v, err := s.Int(n.key, 0)
+		if err != nil {
+			return nil, err
+		}
+		if v < 0 {
+			return nil, fmt.Errorf("%s must not be negative", n.key)
+		}
+		if v > 0 {
+			partitioning = append(partitioning, n.opt(uint64(v)))
+		}
+	}
+	if len(partitioning) > 0 {
+		opts = append(opts, options.WithPartitioningSettings(partitioning...))
+	}
+
+	uniform, err := s.Int("uniformPartitions", 0)
+	if err != nil {
+		return nil, err
+	}
+	if uniform < 0 {
+		return nil, fmt.Errorf("uniformPartitions must not be negative")
+	}
+	if uniform > 0 {
+		opts = append(opts, options.WithPartitions(options.WithUniformPartitions(uint64(uniform))))
+	}
+
+	switch c := s.Setting("compression", ""); c {
+	case "":
+	case "none", "lz4":
+		compression := options.ColumnFamilyCompressionNone
+		if c == "lz4" {
+			compression = options.ColumnFamilyCompressionLZ4
+		}
+		// Columns without a family belong to "default".
+		opts = append(opts, options.WithColumnFamilies(options.ColumnFamily{Name: "default", Compression: compression}))
+	default:
+		return nil, fmt.Errorf("unknown compression %q (want none or lz4)", c)
+	}
+	return opts, nil
+}
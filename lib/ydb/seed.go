@@ -2,10 +2,13 @@ package ydb
 
 import (
 	"context"
-	"encoding/json"
+	"db-bench/lib/conf"
+	"db-bench/lib/seed"
+	"db-bench/lib/workload"
 	"fmt"
 	"log"
 
+	"github.com/ydb-platform/ydb-go-sdk/v3"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table/options"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table/types"
@@ -16,58 +19,94 @@ func (t *YDBTester) Seed(ctx context.Context) error {
 
 	// Create table
 	err := t.db.Table().Do(ctx, func(ctx context.Context, s table.Session) error {
-		return s.CreateTable(ctx, tablePath,
+		return s.CreateTable(ctx, tablePath, append([]options.CreateTableOption{
 			options.WithColumn("id", types.TypeInt64),
 			options.WithColumn("experiment_name", types.TypeUTF8),
 			options.WithColumn("targeting_rules", types.TypeJSON),
 			options.WithPrimaryKeyColumn("id"),
-		)
+		}, t.tableOptions...)...)
 	})
 	if err != nil {
 		log.Printf("Warning: Failed to create table (may already exist): %v", err)
 	}
+	if t.cfg.Query.Index {
+		log.Printf("Warning: YDB cannot index inside a Json column, query.index is ignored")
+	}
 
-	log.Println("YDB: Writing rows...")
-
-	// Batch size for YDB
-	batchSize := 1000
+	return seed.Run(ctx, "YDB", t.cfg, t, func(ctx context.Context, rows []conf.ExperimentRule) error {
+		values := make([]types.Value, len(rows))
+		for i, rule := range rows {
+			values[i] = types.StructValue(
+				types.StructFieldValue("id", types.Int64Value(rule.ID)),
+				types.StructFieldValue("experiment_name", types.UTF8Value(rule.ExperimentName)),
+				types.StructFieldValue("targeting_rules", types.JSONValue(rule.TargetingRules)),
+			)
+		}
+		return t.db.Table().Do(ctx, func(ctx context.Context, s table.Session) error {
+			return s.BulkUpsert(ctx, tablePath, types.ListValue(values...))
+		})
+	})
+}
 
-	for i := 1; i <= t.cfg.RecordCount; i += batchSize {
-		err := t.db.Table().Do(ctx, func(ctx context.Context, s table.Session) error {
-			rows := make([]types.Value, 0, batchSize)
+func (t *YDBTester) Count(ctx context.Context) (int64, error) {
+	var n uint64
+	err := t.aggregate(ctx, "SELECT COUNT(*) FROM `%s`", &n)
+	return int64(n), err
+}
 
-			for j := i; j <= i+batchSize-1 && j <= t.cfg.RecordCount; j++ {
-				targetingRulesMap := map[string]interface{}{"country": "US"}
-				targetingRulesJSON, err := json.Marshal(targetingRulesMap)
-				if err != nil {
-					return err
-				}
+func (t *YDBTester) MaxID(ctx context.Context) (int64, error) {
+	var id int64
+	err := t.aggregate(ctx, "SELECT MAX(id) FROM `%s`", &id)
+	return id, err
+}
 
-				rows = append(rows, types.StructValue(
-					types.StructFieldValue("id", types.Int64Value(int64(j))),
-					types.StructFieldValue("experiment_name", types.UTF8Value(fmt.Sprintf("Test %d", j))),
-					types.StructFieldValue("targeting_rules", types.JSONValue(string(targetingRulesJSON))),
-				))
+// aggregate runs a single-value query over the whole table as a scan query,
+// which unlike a data query is not limited in how many rows it reads.
+func (t *YDBTester) aggregate(ctx context.Context, query string, dst any) error {
+	return t.db.Table().Do(ctx, func(ctx context.Context, s table.Session) error {
+		res, err := s.StreamExecuteScanQuery(ctx, fmt.Sprintf(query, t.getTablePath()), table.NewQueryParameters())
+		if err != nil {
+			return err
+		}
+		defer res.Close()
+		for res.NextResultSet(ctx) {
+			if res.NextRow() {
+				// MAX over an empty table is NULL, which scans as 0.
+				return res.ScanWithDefaults(dst)
 			}
+		}
+		return res.Err()
+	})
+}
 
-			return s.BulkUpsert(ctx, tablePath, types.ListValue(rows...))
-		})
-
+func (t *YDBTester) Get(ctx context.Context, id int64) (conf.ExperimentRule, error) {
+	rule := conf.ExperimentRule{ID: id}
+	err := t.db.Table().Do(ctx, func(ctx context.Context, s table.Session) error {
+		_, res, err := s.Execute(ctx, t.readTx, t.q.get,
+			table.NewQueryParameters(
+				table.ValueParam("$id", types.Int64Value(id)),
+			),
+		)
 		if err != nil {
-			log.Printf("Warning: YDB bulk upsert failed for batch starting at %d: %v", i, err)
+			return err
 		}
+		defer res.Close()
 
-		if i%10000 == 0 || i+batchSize > t.cfg.RecordCount {
-			log.Printf("YDB: %d records prepared...", min(i+batchSize-1, t.cfg.RecordCount))
+		if res.NextResultSet(ctx) && res.NextRow() {
+			return res.ScanWithDefaults(&rule.ID, &rule.ExperimentName, &rule.TargetingRules)
 		}
-	}
-
-	return nil
+		return workload.ErrNotFound
+	})
+	return rule, err
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+func (t *YDBTester) Drop(ctx context.Context) error {
+	err := t.db.Table().Do(ctx, func(ctx context.Context, s table.Session) error {
+		return s.DropTable(ctx, t.getTablePath())
+	})
+	if ydb.IsOperationErrorSchemeError(err) {
+		// the table does not exist
+		return nil
 	}
-	return b
+	return err
 }
@@ -7,23 +7,41 @@ import (
 
 	"github.com/ydb-platform/ydb-go-sdk/v3"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table"
+	"github.com/ydb-platform/ydb-go-sdk/v3/table/options"
 )
 
 type YDBTester struct {
 	db  *ydb.Driver
 	cfg *conf.Config
+	q   queries
+	// CREATE TABLE options of the schema variant
+	tableOptions []options.CreateTableOption
+	// transaction of reads
+	readTx *table.TransactionControl
 }
 
 func NewYDBTester(ctx context.Context, cfg *conf.Config) (*YDBTester, error) {
-	db, err := ydb.Open(ctx, cfg.URI)
+	opts, err := tableOptions(cfg.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("ydb.schemas.%s: %w", cfg.Schema.Name, err)
+	}
+	open, err := openOptions(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ydb.options: %w", err)
+	}
+	db, err := ydb.Open(ctx, cfg.URI, open...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &YDBTester{
-		db:  db,
-		cfg: cfg,
-	}, nil
+	t := &YDBTester{
+		db:           db,
+		cfg:          cfg,
+		tableOptions: opts,
+		readTx:       readTxControl(cfg.Consistency),
+	}
+	t.q = newQueries(t.getTablePath(), cfg.Query.Attribute)
+	return t, nil
 }
 
 func (t *YDBTester) Close() {